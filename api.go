@@ -1,13 +1,22 @@
 package h5go
 
+import (
+	"fmt"
+	"sync/atomic"
+)
 import (
 	"github.com/valoox/h5go/core"
 	"github.com/valoox/h5go/h5d"
 	"github.com/valoox/h5go/h5f"
 	"github.com/valoox/h5go/h5g"
 	"github.com/valoox/h5go/h5l"
+	"github.com/valoox/h5go/h5o"
+	"github.com/valoox/h5go/h5p"
 )
 
+// The properties used by Open and Create.
+//
+// Deprecated: use the FileOptions of OpenFile and CreateFile instead.
 var (
 	FileAccess = h5f.DefaultAccess
 	FileCreate = h5f.DefaultCreate
@@ -50,6 +59,24 @@ func (l *loc) defaults() (err error) {
 	return err
 }
 
+// Releases the default options, which are shared with all the
+// locations copied from this one
+func (l *loc) release() error {
+	var first error
+	for _, p := range []interface {
+		Id() h5p.Property
+		Close() error
+	}{l.lcreate, l.laccess, l.gcreate, l.gaccess, l.dcreate, l.daccess} {
+		if p.Id() <= 0 {
+			continue
+		}
+		if err := p.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
 // Copies the location parameters into a new location options
 func (l *loc) copyTo(where core.Location, newpath core.Path) *loc {
 	return &loc{
@@ -86,6 +113,58 @@ func (l *loc) Get(path core.Path) (Group, error) {
 	}, err
 }
 
+// Opens the object at the given path from this location, whatever
+// its type (see h5o.Open)
+func (l *loc) Object(path core.Path) (h5o.Object, error) {
+	return h5o.Open(l.where, path, l.laccess)
+}
+
+// Checks whether an object exists at the given path from this
+// location
+func (l *loc) Exists(path core.Path) (bool, error) {
+	return h5o.Exists(l.where, path, l.laccess)
+}
+
+// Describes the object at the given path from this location
+func (l *loc) Info(path core.Path) (h5o.Info, error) {
+	return h5o.GetInfoByName(l.where, path, l.laccess)
+}
+
+// Copies the object at the given path (with all its members, for
+// groups) to the destination, which can be a group or a file other
+// than the one of this location. The flags are the copy options of
+// the h5o package, and can be zero.
+func (l *loc) CopyTo(path core.Path, dst core.Location,
+	dpath core.Path, flags h5o.CopyFlag) error {
+	cpy := h5o.DefaultCopy
+	if flags != 0 {
+		var err error
+		if cpy, err = h5o.Copying(); err != nil {
+			return err
+		}
+		defer cpy.Close()
+		if err := cpy.SetFlags(flags); err != nil {
+			return err
+		}
+	}
+	return h5o.Copy(l.where, path, dst, dpath, cpy, l.lcreate)
+}
+
+// Creates a soft link at the given path, pointing to the target path
+func (l *loc) SoftLink(path core.Path, target core.Path) error {
+	_, err := h5l.Soft(target, l.where, path, l.lcreate, l.laccess)
+	return err
+}
+
+// Creates an external link at the given path, pointing to the target
+// path in another file
+func (l *loc) ExternalLink(path core.Path, file string,
+	target core.Path) error {
+	_, err := h5l.External(file, target, l.where, path, l.lcreate,
+		l.laccess)
+	return err
+}
+
 // Wraps a h5g.Group handle and adds methods and features
 type Group struct {
 	*loc      // Embeds the location
@@ -100,40 +179,124 @@ type File struct {
 }
 
 // Opens a file, stating whether it is read-only (rw = false) or
-// if it can be edited (rw = true)
+// if it can be edited (rw = true). The file is accessed using the
+// FileAccess properties (see OpenFile to select a driver).
 func Open(path string, rw bool) (*File, error) {
-	var flag h5f.Flag
-	if rw {
-		flag = h5f.RW
-	} else {
-		flag = h5f.RO
-	}
-	fid, err := h5f.Open(path, flag, FileAccess)
-	out := &File{
-		loc:  new(loc),
-		File: fid,
-		path: path,
+	return OpenFile(path, rw, legacy())
+}
+
+// Opens a file with the given options (see Open), which can be nil
+func OpenFile(path string, rw bool, opts *FileOptions) (*File, error) {
+	if opts == nil {
+		opts = &FileOptions{}
 	}
-	out.loc.at = ""
-	out.loc.where = out
-	out.loc.in = out
+	acc, release, err := opts.access()
 	if err != nil {
-		return out, err
+		return nil, err
 	}
-	return out, out.defaults()
+	defer release()
+	mode := flag(rw)
+	if opts.SWMR && rw {
+		mode |= h5f.SWMR_WRITE
+	} else if opts.SWMR {
+		mode |= h5f.SWMR_READ
+	}
+	fid, err := h5f.Open(path, mode, acc)
+	return wrap(fid, path, err)
 }
 
 // Creates a new file. If the overwrite (`ow`) boolean is set,
 // overwrite any existing file.
-// Otherwise, this raises an error if the file already exists
+// Otherwise, this raises an error if the file already exists.
+// The file is created using the FileCreate and FileAccess properties
+// (see CreateFile to select a driver).
 func Create(path string, ow bool) (*File, error) {
-	var flag h5f.Flag
+	return CreateFile(path, ow, legacy())
+}
+
+// Creates a new file with the given options (see Create), which can
+// be nil
+func CreateFile(path string, ow bool, opts *FileOptions) (*File, error) {
+	if opts == nil {
+		opts = &FileOptions{}
+	}
+	acc, release, err := opts.access()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	mode := h5f.CREATE
 	if ow {
-		flag = h5f.TRUNC
-	} else {
-		flag = h5f.CREATE
+		mode = h5f.TRUNC
+	}
+	fid, err := h5f.Create(path, mode, opts.Create, acc)
+	return wrap(fid, path, err)
+}
+
+// Opens a file from its content in memory (see File.Image), which
+// is copied. If the file is editable (rw = true), the changes are
+// only made in memory. The file is accessed using the FileAccess
+// properties (see OpenImageFile to pass options).
+func OpenImage(image []byte, rw bool) (*File, error) {
+	return OpenImageFile(image, rw, legacy())
+}
+
+// Opens a file from its content in memory with the given options
+// (see OpenImage), which can be nil. The file is held by the core
+// driver, so they cannot select another driver.
+func OpenImageFile(image []byte, rw bool, opts *FileOptions) (*File, error) {
+	if opts == nil {
+		opts = &FileOptions{}
+	}
+	if err := opts.inMemory(); err != nil {
+		return nil, err
+	}
+	acc, release, err := opts.access()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	fid, err := h5f.OpenImage(image, flag(rw), acc)
+	return wrap(fid, "", err)
+}
+
+// Counts the files created in memory, to give them distinct names
+var inmemory int64
+
+// Creates a new file in memory, which is never written to the disk.
+// Its content can be obtained using File.Image. The file is created
+// using the FileCreate and FileAccess properties (see
+// CreateInMemoryFile to pass options).
+func CreateInMemory() (*File, error) {
+	return CreateInMemoryFile(legacy())
+}
+
+// Creates a new file in memory with the given options (see
+// CreateInMemory), which can be nil. The file is held by the core
+// driver, so they cannot select another driver.
+func CreateInMemoryFile(opts *FileOptions) (*File, error) {
+	var mem FileOptions
+	if opts != nil {
+		mem = *opts
+	}
+	if err := mem.inMemory(); err != nil {
+		return nil, err
 	}
-	fid, err := h5f.Create(path, flag, FileCreate, FileAccess)
+	mem.Driver = Core(64*core.KB, false)
+	path := fmt.Sprintf("memory-%d", atomic.AddInt64(&inmemory, 1))
+	return CreateFile(path, false, &mem)
+}
+
+// The access flag for read-only or editable files
+func flag(rw bool) h5f.Flag {
+	if rw {
+		return h5f.RW
+	}
+	return h5f.RO
+}
+
+// Wraps the file id, initialising the default options
+func wrap(fid h5f.File, path string, err error) (*File, error) {
 	out := &File{
 		loc:  new(loc),
 		File: fid,
@@ -147,3 +310,39 @@ func Create(path string, ow bool) (*File, error) {
 	}
 	return out, out.defaults()
 }
+
+// Closes the file, along with the default options of its locations.
+// The groups obtained from the file should be closed beforehand.
+func (f *File) Close() error {
+	err := f.loc.release()
+	if cerr := f.File.Close(); cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+// The names of the links in the root group, in alphanumerical order
+func (f *File) Keys() ([]string, error) {
+	return h5g.Keys(f.File, core.ByName, core.Increasing)
+}
+
+// Calls the function on each link of the root group
+// (see h5l.Iterate)
+func (f *File) Iterate(idx core.Index, order core.Order,
+	fn h5l.IterFunc) error {
+	return h5l.Iterate(f.File, idx, order, fn)
+}
+
+// Recursively calls the function on all the links of the file
+// (see h5l.Visit)
+func (f *File) Visit(idx core.Index, order core.Order,
+	fn h5l.IterFunc) error {
+	return h5l.Visit(f.File, idx, order, fn)
+}
+
+// Recursively calls the function on all the objects of the file
+// (see h5o.Visit)
+func (f *File) VisitObjects(idx core.Index, order core.Order,
+	fn h5o.VisitFunc) error {
+	return h5o.Visit(f.File, idx, order, fn)
+}
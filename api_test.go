@@ -0,0 +1,244 @@
+package h5go
+
+import (
+	"os"
+	"testing"
+)
+import (
+	"github.com/valoox/h5go/h5f"
+	"github.com/valoox/h5go/h5l"
+	"github.com/valoox/h5go/h5o"
+)
+
+// Copies a group between two files, and inspects the copy
+func TestCopy(t *testing.T) {
+	const src, dst = "./copy_src.h5", "./copy_dst.h5"
+	f, err := Create(src, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(src)
+	defer f.Close()
+	g, err := f.NewGroup("run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	if err := g.SetAttr("operator", "someone"); err != nil {
+		t.Fatal(err)
+	}
+	ds, err := g.NewDataset("samples", []float32{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ds.Close()
+	out, err := Create(dst, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(dst)
+	defer out.Close()
+	if err := f.CopyTo("run", out, "copied", h5o.WITHOUT_ATTR); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := out.Exists("copied/samples"); err != nil {
+		t.Fatal(err)
+	} else if !ok {
+		t.Fatal("Dataset not copied")
+	}
+	info, err := out.Info("copied")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Type != h5o.GROUP || info.NumAttrs != 0 {
+		t.Fatalf("Wrong copy: %+v", info)
+	}
+	obj, err := out.Object("copied/samples")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer obj.Close()
+	if T, err := obj.Type(); err != nil {
+		t.Fatal(err)
+	} else if T != h5o.DATASET {
+		t.Fatalf("Expecting a dataset, got a %s", T)
+	}
+}
+
+// Stitches two files with an external link, and inspects it
+func TestExternalLink(t *testing.T) {
+	const main, part = "./elink_main.h5", "./elink_part.h5"
+	p, err := Create(part, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(part)
+	ds, err := p.NewDataset("values", []int64{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds.Close()
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Create(main, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(main)
+	defer f.Close()
+	if err := f.ExternalLink("part", part, "/values"); err != nil {
+		t.Fatal(err)
+	}
+	info, err := h5l.GetInfo(f, "part", h5l.DefaultAccess)
+	if err != nil {
+		t.Fatal(err)
+	} else if info.Type != h5l.EXTERNAL {
+		t.Fatalf("Expecting an external link, got %v", info.Type)
+	}
+	file, target, err := h5l.Value(f, "part", h5l.DefaultAccess)
+	if err != nil {
+		t.Fatal(err)
+	} else if file != part || target != "/values" {
+		t.Fatalf("Wrong link value: %s:%s", file, target)
+	}
+	linked, err := f.OpenDataset("part")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer linked.Close()
+	var values []int64
+	if err := linked.ReadAll(&values); err != nil {
+		t.Fatal(err)
+	} else if len(values) != 3 || values[2] != 3 {
+		t.Fatalf("Wrong values through the link: %v", values)
+	}
+}
+
+// Builds a file in memory, and opens it back from its image
+func TestImage(t *testing.T) {
+	f, err := CreateInMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	ds, err := f.NewDataset("values", []int32{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.Close(); err != nil {
+		t.Fatal(err)
+	}
+	image, err := f.Image()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(image) < 8 || string(image[1:4]) != "HDF" {
+		t.Fatalf("Not an HDF5 image: %q", image[:8])
+	}
+	g, err := OpenImage(image, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	in, err := g.OpenDataset("values")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	var values []int32
+	if err := in.ReadAll(&values); err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 3 || values[2] != 3 {
+		t.Fatalf("Wrong values: %v", values)
+	}
+	h, err := OpenImageFile(image, false, &FileOptions{Latest: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenImageFile(image, false,
+		&FileOptions{Driver: Stdio()}); err == nil {
+		t.Fatal("Expected an error selecting a driver for an image")
+	}
+	if _, err := CreateInMemoryFile(&FileOptions{Driver: Stdio()}); err == nil {
+		t.Fatal("Expected an error selecting a driver for a file in memory")
+	}
+}
+
+// Creates files through different drivers
+func TestDrivers(t *testing.T) {
+	for _, test := range []struct {
+		path   string
+		driver Driver
+		files  []string
+	}{
+		{"./stdio.h5", Stdio(), []string{"./stdio.h5"}},
+		{"./family-%d.h5", Family(1 << 20), []string{"./family-0.h5"}},
+		{"./split", Split("-m.h5", "-r.h5"),
+			[]string{"./split-m.h5", "./split-r.h5"}},
+		{"./logged.h5", Log("./logged.log", h5f.LOG_LOC_IO, 0),
+			[]string{"./logged.h5", "./logged.log"}},
+	} {
+		f, err := CreateFile(test.path, true, &FileOptions{Driver: test.driver})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range test.files {
+			defer os.Remove(name)
+		}
+		data := make([]float64, 1000)
+		ds, err := f.NewDataset("data", data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ds.Close()
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range test.files {
+			if _, err := os.Stat(name); err != nil {
+				t.Errorf("Missing file for %s: %s", test.path, err)
+			}
+		}
+		g, err := OpenFile(test.path, false, &FileOptions{Driver: test.driver})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := g.Exists("data"); err != nil || !ok {
+			t.Errorf("Missing dataset in %s (%v)", test.path, err)
+		}
+		g.Close()
+	}
+	acc, err := h5f.Access()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer acc.Close()
+	if err := Family(2048)(acc); err != nil {
+		t.Fatal(err)
+	}
+	if d, err := acc.GetDriver(); err != nil || d != h5f.FAMILY {
+		t.Errorf("Wrong driver: %s (%v)", d, err)
+	}
+	if size, member, err := acc.GetFamily(); err != nil || size != 2048 {
+		t.Errorf("Wrong family size: %v (%v)", size, err)
+	} else {
+		member.Close()
+	}
+	if err := Split("-meta", "-raw")(acc); err != nil {
+		t.Fatal(err)
+	}
+	if meta, raw, err := acc.GetSplit(); err != nil || meta != "-meta" || raw != "-raw" {
+		t.Errorf("Wrong split extensions: %q %q (%v)", meta, raw, err)
+	}
+	if err := Log("", h5f.LOG_ALL, 0)(acc); err != nil {
+		t.Fatal(err)
+	}
+	if d, err := acc.GetDriver(); err != nil || d != h5f.LOG {
+		t.Errorf("Wrong driver: %s (%v)", d, err)
+	}
+}
@@ -0,0 +1,251 @@
+package h5go
+
+import (
+	"fmt"
+	"reflect"
+)
+import (
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5d"
+	"github.com/valoox/h5go/h5s"
+	"github.com/valoox/h5go/h5t"
+)
+
+// Appends rows to a dataset growing without limit along its first
+// axis. Rows are buffered in memory, and written (extending the
+// dataset) once a whole chunk of rows has been gathered, or when the
+// appender is flushed or closed.
+type Appender struct {
+	ds    *Dataset      // The dataset being appended to
+	rtype reflect.Type  // The Go type of the rows
+	batch int           // The number of rows written at once
+	rows  reflect.Value // The pending rows, as a slice of rtype
+}
+
+// Creates (or opens, if it already exists) a dataset at the given
+// path, to which rows can be appended. The row provided is only used
+// as a prototype: the datatype of the dataset is inferred from its
+// elements, and its shape (for arrays and slices) gives the shape of
+// each row of the dataset, which can be of any type supported by
+// h5t.Parse.
+// When creating the dataset, it is chunked along the first axis by
+// `batch` rows, which is also the number of rows buffered before
+// each write; the options then apply to the creation properties.
+// When opening an existing dataset, it must be unlimited along its
+// first axis and have rows of the same shape and a compatible type,
+// and its own chunk size is used instead.
+func (l *loc) NewAppender(path core.Path, row interface{}, batch int,
+	opts ...Option) (*Appender, error) {
+	v := reflect.ValueOf(row)
+	if !v.IsValid() {
+		return nil, fmt.Errorf("Nothing provided")
+	}
+	shape, elem := shapeof(v)
+	out := &Appender{
+		rtype: v.Type(),
+		batch: batch,
+	}
+	exists, err := l.Exists(path)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		if out.ds, err = l.openAppendable(path, shape, elem); err != nil {
+			return nil, err
+		}
+		crt, err := out.ds.Creation()
+		if err != nil {
+			out.ds.Close()
+			return nil, err
+		}
+		defer crt.Close()
+		chunk, err := crt.GetChunk()
+		if err != nil {
+			out.ds.Close()
+			return nil, err
+		}
+		out.batch = chunk[0]
+	} else {
+		T, err := h5t.ParseType(elem, l.where)
+		if err != nil {
+			return nil, err
+		}
+		defer T.Close()
+		if out.ds, err = l.newAppendable(path, T, shape, batch,
+			opts); err != nil {
+			return nil, err
+		}
+	}
+	out.rows = reflect.MakeSlice(reflect.SliceOf(out.rtype), 0, out.batch)
+	return out, nil
+}
+
+// Creates an empty dataset of rows of the given type and shape,
+// unlimited along its first axis and chunked by `batch` rows
+func (l *loc) newAppendable(path core.Path, T h5t.Datatype,
+	shape []int, batch int, opts []Option) (*Dataset, error) {
+	if batch <= 0 {
+		return nil, fmt.Errorf("Invalid batch size: %v", batch)
+	}
+	dims := append([]int{0}, shape...)
+	maxs := append([]int{-1}, shape...)
+	chunk := append([]int{batch}, shape...)
+	return l.CreateDataset(path, T, dims, maxs,
+		append([]Option{Chunks(chunk...)}, opts...)...)
+}
+
+// Opens an existing dataset, checking that rows can be appended to it
+func (l *loc) openAppendable(path core.Path, shape []int,
+	elem reflect.Type) (*Dataset, error) {
+	ds, err := l.OpenDataset(path)
+	if err != nil {
+		return nil, err
+	}
+	S, err := ds.Shape()
+	if err != nil {
+		ds.Close()
+		return nil, err
+	}
+	defer S.Close()
+	_, maxs, err := S.GetDims()
+	if err != nil {
+		ds.Close()
+		return nil, err
+	}
+	if len(maxs) == 0 || maxs[0] >= 0 {
+		ds.Close()
+		return nil, fmt.Errorf("Dataset %s is not unlimited along its first axis", path)
+	}
+	if !reflect.DeepEqual(ds.dims[1:], shape) {
+		ds.Close()
+		return nil, fmt.Errorf("Invalid row shape: expecting %v, got %v", ds.dims[1:], shape)
+	}
+	if err := compatible(ds.dtype, elem); err != nil {
+		ds.Close()
+		return nil, err
+	}
+	return ds, nil
+}
+
+// The dataset being appended to
+func (a *Appender) Dataset() *Dataset { return a.ds }
+
+// The total number of rows, including the ones not yet written
+func (a *Appender) Len() int { return a.ds.dims[0] + a.rows.Len() }
+
+// Appends rows, which must have the type of the prototype row
+// provided on creation, and the shape of the rows of the dataset.
+// Rows are written once a batch is complete
+func (a *Appender) Append(rows ...interface{}) error {
+	for _, row := range rows {
+		v := reflect.ValueOf(row)
+		if !v.IsValid() || v.Type() != a.rtype {
+			return fmt.Errorf("Invalid row: expecting %s, got %T", a.rtype, row)
+		}
+		if !conforms(v, a.ds.dims[1:]) {
+			return fmt.Errorf("Invalid row: expecting shape %v", a.ds.dims[1:])
+		}
+		a.rows = reflect.Append(a.rows, v)
+		if a.rows.Len() >= a.batch {
+			if err := a.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Writes the pending rows, extending the dataset
+func (a *Appender) Flush() error {
+	n := a.rows.Len()
+	if n == 0 {
+		return nil
+	}
+	buf, err := newBuffer(a.rows.Interface(), a.ds.in)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+	if err := a.ds.extend(buf); err != nil {
+		return err
+	}
+	a.rows = a.rows.Slice(0, 0)
+	return nil
+}
+
+// Flushes the pending rows and closes the dataset, which is closed
+// even if the rows cannot be written
+func (a *Appender) Close() error {
+	err := a.Flush()
+	if cerr := a.ds.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Writes the rows held by the buffer at the end of the dataset,
+// extending it along its first axis
+func (d *Dataset) extend(buf *buffer) error {
+	if len(buf.dims) != len(d.dims) ||
+		!reflect.DeepEqual(buf.dims[1:], d.dims[1:]) {
+		return fmt.Errorf("Invalid shape: cannot append %v to %v", buf.dims, d.dims)
+	}
+	dims := append([]int(nil), d.dims...)
+	start := make([]uint, len(dims))
+	size := make([]uint, len(dims))
+	for i, n := range buf.dims {
+		size[i] = uint(n)
+	}
+	start[0] = uint(dims[0])
+	dims[0] += buf.dims[0]
+	if err := d.SetDims(dims); err != nil {
+		return err
+	}
+	S, err := d.Shape()
+	if err != nil {
+		return err
+	}
+	defer S.Close()
+	if err := h5s.Hyperslab(S).Set(start, nil, size, nil); err != nil {
+		return err
+	}
+	return d.Write(buf, S, h5d.DefaultXfer)
+}
+
+// Selects `n` rows of the dataset along its first axis, starting at
+// `start`. The dataspace returned should be closed after use
+func (d *Dataset) rows(start, n int) (h5s.Dataspace, error) {
+	if len(d.dims) == 0 || start < 0 || n < 0 || start+n > d.dims[0] {
+		return -1, fmt.Errorf("Rows %v-%v out of range for dimensions %v",
+			start, start+n, d.dims)
+	}
+	offset := make([]uint, len(d.dims))
+	size := make([]uint, len(d.dims))
+	for i, dim := range d.dims {
+		size[i] = uint(dim)
+	}
+	offset[0], size[0] = uint(start), uint(n)
+	S, err := d.Shape()
+	if err != nil {
+		return -1, err
+	}
+	if err := h5s.Hyperslab(S).Set(offset, nil, size, nil); err != nil {
+		S.Close()
+		return -1, err
+	}
+	return S, nil
+}
+
+// Reads `n` rows of the dataset along its first axis, starting at
+// `start`, into the value pointed by `out`
+func (d *Dataset) readRows(start, n int, out interface{}) error {
+	S, err := d.rows(start, n)
+	if err != nil {
+		return err
+	}
+	defer S.Close()
+	dims := append([]int{n}, d.dims[1:]...)
+	return readInto(d.dtype, dims, d.in, func(buf h5d.OBuffer) error {
+		return d.Read(buf, S, h5d.DefaultXfer)
+	}, out)
+}
@@ -0,0 +1,81 @@
+package h5go
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+// Appends rows to a dataset, reopening it in between
+func TestAppender(t *testing.T) {
+	const testfile = "./appender.h5"
+	f, err := Create(testfile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(testfile)
+	defer f.Close()
+	type Record struct {
+		Time  int64
+		Value [2]float64
+	}
+	app, err := f.NewAppender("records", Record{}, 4, Deflate(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 6; i++ {
+		r := Record{int64(i), [2]float64{float64(i), -float64(i)}}
+		if err := app.Append(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// One chunk has been written, the other rows are pending
+	if n := app.Dataset().Dims()[0]; n != 4 {
+		t.Fatalf("Expected 4 written rows, got %v", n)
+	}
+	if err := app.Append(1.0); err == nil {
+		t.Fatal("Expected an error appending a wrong type")
+	}
+	if err := app.Close(); err != nil {
+		t.Fatal(err)
+	}
+	app, err = f.NewAppender("records", Record{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Append(Record{Time: 6}); err != nil {
+		t.Fatal(err)
+	}
+	if n := app.Len(); n != 7 {
+		t.Fatalf("Expected 7 rows, got %v", n)
+	}
+	if err := app.Close(); err != nil {
+		t.Fatal(err)
+	}
+	vecs, err := f.NewAppender("vectors", []float64{0, 0}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vecs.Append([]float64{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := vecs.Append([]float64{1, 2, 3}); err == nil {
+		t.Fatal("Expected an error appending a row of the wrong shape")
+	}
+	if err := vecs.Close(); err != nil {
+		t.Fatal(err)
+	}
+	ds, err := f.OpenDataset("records")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ds.Close()
+	var records []Record
+	if err := ds.ReadAll(&records); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 7 || records[6].Time != 6 ||
+		!reflect.DeepEqual(records[3], Record{3, [2]float64{3, -3}}) {
+		t.Fatalf("Wrong records: %v", records)
+	}
+}
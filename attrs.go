@@ -0,0 +1,134 @@
+package h5go
+
+import (
+	"fmt"
+	"unsafe"
+)
+import (
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5a"
+	"github.com/valoox/h5go/h5d"
+	"github.com/valoox/h5go/h5s"
+	"github.com/valoox/h5go/h5t"
+)
+
+// Sets the attribute of the object to the given value, replacing
+// any existing attribute with the same name.
+// The type of the attribute is inferred using h5t.Parse, and its
+// shape from the (possibly nested) slices and arrays of the value.
+func setattr(obj core.Object, ctxt core.Location, name string,
+	value interface{}) error {
+	if buf, ok := value.(h5d.IBuffer); ok {
+		return writeattr(obj, name, buf)
+	}
+	buf, err := newBuffer(value, ctxt)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+	return writeattr(obj, name, buf)
+}
+
+// Writes the buffer as the attribute of the object, replacing any
+// existing attribute with the same name
+func writeattr(obj core.Object, name string, buf h5d.IBuffer) error {
+	if ok, err := h5a.Exists(obj, name); err != nil {
+		return err
+	} else if ok {
+		if err := h5a.Delete(obj, name); err != nil {
+			return err
+		}
+	}
+	T, err := buf.Type()
+	if err != nil {
+		return err
+	}
+	defer T.Close()
+	S, err := buf.Shape()
+	if err != nil {
+		return err
+	}
+	defer S.Close()
+	attr, err := h5a.Create(obj, name, T, S, h5a.DefaultCreate)
+	if err != nil {
+		return err
+	}
+	defer attr.Close()
+	return attr.Write(buf)
+}
+
+// A fixed-length, null-terminated ASCII string, which is how the
+// high-level HDF5 libraries (H5LT, H5TB, H5DS...) and the tools built
+// on them store their string attributes, rather than the
+// variable-length strings produced from Go strings.
+// It can be passed to SetAttr to store a string in this form.
+type FixedString []byte
+
+// Creates the null-terminated string
+func NewFixedString(s string) FixedString { return append([]byte(s), 0) }
+
+// Implements the h5d.IBuffer interface. The string must hold at least
+// its terminating null (see NewFixedString).
+func (s FixedString) Type() (h5t.Datatype, error) {
+	if len(s) == 0 {
+		return -1, fmt.Errorf("Empty fixed-length string: expecting at least its null terminator")
+	}
+	return h5t.String(len(s), false)
+}
+func (s FixedString) Shape() (h5s.Dataspace, error) { return h5s.CreateScalar() }
+func (s FixedString) ReadPtr() unsafe.Pointer {
+	if len(s) == 0 {
+		return nil
+	}
+	return unsafe.Pointer(&s[0])
+}
+
+// Reads the attribute of the object into the value pointed by `out`
+func getattr(obj core.Object, ctxt core.Location, name string,
+	out interface{}) error {
+	attr, err := h5a.Open(obj, name)
+	if err != nil {
+		return err
+	}
+	defer attr.Close()
+	T, err := attr.Type()
+	if err != nil {
+		return err
+	}
+	defer T.Close()
+	S, err := attr.Shape()
+	if err != nil {
+		return err
+	}
+	defer S.Close()
+	dims, _, err := S.GetDims()
+	if err != nil {
+		return err
+	}
+	return readInto(T, dims, ctxt, attr.Read, out)
+}
+
+// Sets an attribute on this location, replacing any existing
+// attribute with the same name. The value can be a scalar, a string,
+// a structure, or (possibly nested) slices and arrays of those, or
+// an h5d.IBuffer providing its own type and shape (e.g. FixedString).
+func (l *loc) SetAttr(name string, value interface{}) error {
+	return setattr(handle(l.where.At()), l.where, name, value)
+}
+
+// Reads the attribute of this location into `out`, which must be a
+// pointer to a value of a compatible type. Slices are allocated to
+// the size of the attribute.
+func (l *loc) Attr(name string, out interface{}) error {
+	return getattr(handle(l.where.At()), l.where, name, out)
+}
+
+// Checks whether this location has an attribute with this name
+func (l *loc) HasAttr(name string) (bool, error) {
+	return h5a.Exists(handle(l.where.At()), name)
+}
+
+// Deletes the attribute of this location
+func (l *loc) DelAttr(name string) error {
+	return h5a.Delete(handle(l.where.At()), name)
+}
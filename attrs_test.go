@@ -0,0 +1,122 @@
+package h5go
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+// Writes attributes of different kinds and reads them back
+func TestAttrs(t *testing.T) {
+	const testfile = "./attrs.h5"
+	f, err := Create(testfile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(testfile)
+	defer f.Close()
+	g, err := f.NewGroup("grp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	if err := f.SetAttr("version", int32(3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.SetAttr("units", "m/s"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.SetAttr("matrix", [][]float64{{1, 2, 3}, {4, 5, 6}}); err != nil {
+		t.Fatal(err)
+	}
+	var version int32
+	if err := f.Attr("version", &version); err != nil {
+		t.Fatal(err)
+	} else if version != 3 {
+		t.Fatalf("Expected 3, got %v", version)
+	}
+	var units string
+	if err := g.Attr("units", &units); err != nil {
+		t.Fatal(err)
+	} else if units != "m/s" {
+		t.Fatalf("Expected m/s, got %q", units)
+	}
+	var matrix [][]float64
+	if err := g.Attr("matrix", &matrix); err != nil {
+		t.Fatal(err)
+	}
+	if len(matrix) != 2 || len(matrix[1]) != 3 || matrix[1][2] != 6 {
+		t.Fatalf("Wrong matrix read back: %v", matrix)
+	}
+	var flat []float64
+	if err := g.Attr("matrix", &flat); err != nil {
+		t.Fatal(err)
+	} else if len(flat) != 6 {
+		t.Fatalf("Expected 6 elements, got %v", flat)
+	}
+	if err := g.DelAttr("units"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := g.HasAttr("units"); err != nil {
+		t.Fatal(err)
+	} else if ok {
+		t.Fatal("Attribute units still exists after deletion")
+	}
+	if err := g.SetAttr("padded", NewFixedString("ab  ")); err != nil {
+		t.Fatal(err)
+	}
+	var padded string
+	if err := g.Attr("padded", &padded); err != nil {
+		t.Fatal(err)
+	} else if padded != "ab  " {
+		t.Fatalf("Expected the trailing spaces to be kept, got %q", padded)
+	}
+	if err := g.SetAttr("empty", FixedString(nil)); err == nil {
+		t.Fatal("Expected an error storing an empty fixed-length string")
+	}
+	one := int32(1)
+	if err := g.SetAttr("pointers", []*int32{&one}); err == nil {
+		t.Fatal("Expected an error storing pointers")
+	}
+}
+
+// Writes structures holding strings, which are stored as C strings
+func TestStructStrings(t *testing.T) {
+	const testfile = "./structs.h5"
+	f, err := Create(testfile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(testfile)
+	defer f.Close()
+	type Point struct {
+		X, Y float64
+		Name string
+	}
+	type Shape struct {
+		Name   string
+		Points [2]Point
+		Tags   [2]string
+	}
+	shapes := []Shape{
+		{"line", [2]Point{{0, 0, "start"}, {1, 1, "end"}}, [2]string{"a", ""}},
+		{"dot", [2]Point{{2, 2, "here"}, {}}, [2]string{"b", "c"}},
+	}
+	if err := f.SetAttr("shapes", shapes); err != nil {
+		t.Fatal(err)
+	}
+	var back []Shape
+	if err := f.Attr("shapes", &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, shapes) {
+		t.Fatalf("Expected %v, got %v", shapes, back)
+	}
+	type hidden struct {
+		Name  string
+		value int
+	}
+	if err := f.SetAttr("hidden", hidden{"x", 1}); err == nil {
+		t.Fatal("Expected an error storing strings with unexported fields")
+	}
+}
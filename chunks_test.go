@@ -0,0 +1,152 @@
+package h5go
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+import (
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5d"
+	"github.com/valoox/h5go/h5s"
+	"github.com/valoox/h5go/h5t"
+)
+
+// Enumerates the chunks of a compressed dataset, and copies one of
+// them as is into a sparse dataset
+func TestChunks(t *testing.T) {
+	const testfile = "./chunks.h5"
+	defer os.Remove(testfile)
+	f, err := Create(testfile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	T, err := h5t.Int32()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer T.Close()
+	src, err := f.CreateDataset("src", T, []int{12}, nil, Chunks(4), Deflate(6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	data := make([]int32, 12)
+	for i := range data {
+		data[i] = int32(i)
+	}
+	if err := src.WriteAll(data); err != nil {
+		t.Fatal(err)
+	}
+	dst, err := f.CreateDataset("dst", T, []int{12}, nil, Chunks(4), Deflate(6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+
+	// Enumeration
+	if n, err := src.GetNumChunks(h5s.ALL); err != nil {
+		t.Fatal(err)
+	} else if n != 3 {
+		t.Fatalf("Expected 3 chunks, got %v", n)
+	}
+	var chunks []h5d.ChunkInfo
+	for i := 0; i < 3; i++ {
+		info, err := src.GetChunkInfo(h5s.ALL, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, info)
+	}
+	for i, chunk := range chunks {
+		if len(chunk.Offset) != 1 || chunk.Offset[0] != uint(4*i) {
+			t.Fatalf("Wrong offset of chunk %v: %v", i, chunk.Offset)
+		}
+		if !chunk.Allocated() || chunk.Size == 0 || chunk.Filters != 0 {
+			t.Fatalf("Wrong chunk %v: %+v", i, chunk)
+		}
+		info, err := src.GetChunkInfoByCoord(chunk.Offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Addr != chunk.Addr || info.Size != chunk.Size {
+			t.Fatalf("Expected chunk %+v, got %+v", chunk, info)
+		}
+	}
+	if _, err := src.GetChunkInfoByCoord([]uint{4, 0}); err == nil {
+		t.Fatal("Expected an error with an offset of the wrong rank")
+	}
+	if h5d.IterChunksSupported() {
+		var iterated []h5d.ChunkInfo
+		if err := src.IterChunks(h5d.DefaultXfer, func(info h5d.ChunkInfo) error {
+			iterated = append(iterated, info)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(iterated, chunks) {
+			t.Fatalf("Expected chunks %+v, got %+v", chunks, iterated)
+		}
+		seen := 0
+		if err := src.IterChunks(h5d.DefaultXfer, func(h5d.ChunkInfo) error {
+			seen++
+			return core.Stop
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if seen != 1 {
+			t.Fatalf("Expected the iteration to stop after 1 chunk, got %v", seen)
+		}
+	} else if err := src.IterChunks(h5d.DefaultXfer,
+		func(h5d.ChunkInfo) error { return nil }); err == nil {
+		t.Fatal("Expected an error iterating without support")
+	}
+
+	// Sparse datasets
+	if n, err := dst.GetNumChunks(h5s.ALL); err != nil {
+		t.Fatal(err)
+	} else if n != 0 {
+		t.Fatalf("Expected no chunk, got %v", n)
+	}
+	if info, err := dst.GetChunkInfoByCoord([]uint{4}); err != nil {
+		t.Fatal(err)
+	} else if info.Allocated() {
+		t.Fatalf("Expected an unallocated chunk, got %+v", info)
+	}
+	if _, _, err := dst.ReadChunk([]uint{4}, h5d.DefaultXfer); err == nil {
+		t.Fatal("Expected an error reading an unallocated chunk")
+	}
+
+	// Direct copy of the compressed chunk
+	raw, filters, err := src.ReadChunk([]uint{4}, h5d.DefaultXfer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) != chunks[1].Size || filters != 0 {
+		t.Fatalf("Expected %v bytes with all filters, got %v (mask %v)",
+			chunks[1].Size, len(raw), filters)
+	}
+	if err := dst.WriteChunk([]uint{4}, filters, raw,
+		h5d.DefaultXfer); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := dst.GetNumChunks(h5s.ALL); err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Fatalf("Expected 1 chunk, got %v", n)
+	}
+	var out []int32
+	if err := dst.ReadAll(&out); err != nil {
+		t.Fatal(err)
+	}
+	for i, x := range out {
+		expected := int32(0)
+		if i >= 4 && i < 8 {
+			expected = int32(i)
+		}
+		if x != expected {
+			t.Fatalf("Wrong value at %v: expected %v, got %v", i, expected, x)
+		}
+	}
+}
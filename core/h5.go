@@ -70,6 +70,8 @@ func binsize(size uint) string {
 // loaded properly
 // Wraps the H5open function
 func Init() error {
+	Lock()
+	defer Unlock()
 	return Status(int(C.H5open()), "opening library")
 }
 
@@ -78,6 +80,8 @@ func Init() error {
 // might be useful for really memory-intensive applications
 // Wraps the H5garbage_collect function
 func GC() error {
+	Lock()
+	defer Unlock()
 	return Status(int(C.H5garbage_collect()), "garbage collecting")
 }
 
@@ -87,6 +91,8 @@ func GC() error {
 // main, but should otherwise be avoided
 // Wraps the H5close function
 func Close() error {
+	Lock()
+	defer Unlock()
 	return Status(int(C.H5close()), "closing library")
 }
 
@@ -94,6 +100,8 @@ func Close() error {
 // matches the current version
 // Wraps the H5check_version function
 func CheckVersion(major, minor, release uint) bool {
+	Lock()
+	defer Unlock()
 	return C.H5check_version(C.uint(major),
 		C.uint(minor),
 		C.uint(release)) >= 0
@@ -104,6 +112,8 @@ func CheckVersion(major, minor, release uint) bool {
 // an error (or nil) if it cannot load them
 // Wraps the H5get_libversion function
 func GetVersion() (uint, uint, uint, error) {
+	Lock()
+	defer Unlock()
 	var major, minor, release C.uint
 	if err := Status(int(
 		C.H5get_libversion(&major, &minor, &release)),
@@ -200,6 +210,8 @@ func (self *limits) Block(list, global uint) bool {
 // Sets the new limits in the C code
 // Wraps the H5set_free_list_limits function
 func (self *limits) Set() error {
+	Lock()
+	defer Unlock()
 	return Status(int(C.H5set_free_list_limits(
 		C.int(self.Reg_global),
 		C.int(self.Reg_list),
@@ -30,3 +30,17 @@ func TestLimits(t *testing.T) {
 	}
 	t.Logf("Updated %s", Limits)
 }
+
+// Reports how the calls to the library are serialised
+func TestMode(t *testing.T) {
+	mode := GetMode()
+	t.Logf("Mode: %s", mode)
+	// The lock is reentrant
+	Lock()
+	Lock()
+	if _, _, _, err := GetVersion(); err != nil {
+		t.Fatal(err)
+	}
+	Unlock()
+	Unlock()
+}
@@ -0,0 +1,36 @@
+package core
+
+/*
+#include <hdf5.h>
+*/
+import "C"
+import "errors"
+
+// The index used to traverse the members of a group (or the
+// attributes of an object)
+type Index int
+
+const (
+	// Traverses in alphanumerical order of the names
+	ByName Index = C.H5_INDEX_NAME
+	// Traverses in creation order. This is only available if the
+	// creation order was tracked when the group was created
+	ByCreation Index = C.H5_INDEX_CRT_ORDER
+)
+
+// The order in which an index is traversed
+type Order int
+
+const (
+	Increasing  Order = C.H5_ITER_INC    // Increasing order
+	Decreasing  Order = C.H5_ITER_DEC    // Decreasing order
+	NativeOrder Order = C.H5_ITER_NATIVE // Fastest available order
+)
+
+// Stop can be returned by the callbacks of the iteration functions to
+// end the iteration early. The iteration then returns without error.
+var Stop = errors.New("stop iteration")
+
+// Identifies an object in a file. This replaces the addresses of the
+// objects from HDF5 1.12 onwards, and should be considered opaque.
+type Token [16]byte
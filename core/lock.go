@@ -0,0 +1,51 @@
+package core
+
+import (
+	"github.com/valoox/h5go/internal/lock"
+)
+
+// How the calls to the library are serialised
+type Mode int
+
+const (
+	// The library is thread-safe and serialises the calls itself:
+	// no lock is taken on the Go side
+	Threadsafe Mode = iota
+	// The library is not thread-safe: every call from the packages of
+	// h5go goes through a global lock, so that they can be used from
+	// several goroutines. The calls never run in parallel either way.
+	Locked
+)
+
+// Textual representation of the mode
+func (m Mode) String() string {
+	if m == Threadsafe {
+		return "threadsafe"
+	}
+	return "locked"
+}
+
+// The mode in which the library is used, which depends on whether
+// it was built thread-safe
+// Wraps the H5is_library_threadsafe function
+func GetMode() Mode {
+	if lock.Threadsafe() {
+		return Threadsafe
+	}
+	return Locked
+}
+
+// Takes the global lock serialising the calls to the library, unless
+// it is thread-safe. In both modes, this pins the calling goroutine
+// to its thread until Unlock, so that the error stack of the library
+// (which is kept per thread) can be captured. The lock is reentrant,
+// so that the library can call back into Go code which uses it
+// again. This must be followed by a call to Unlock, and is only
+// needed when calling the library directly through cgo: all the
+// packages of h5go already take it.
+// The callbacks of iterations run with the lock held, so they must
+// not wait on other goroutines using the library.
+func Lock() { lock.Lock() }
+
+// Releases the global lock taken by Lock
+func Unlock() { lock.Unlock() }
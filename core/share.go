@@ -1,6 +1,11 @@
 package core
 
-import "fmt"
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/valoox/h5go/h5e"
+)
 
 // Represents the ID of an HDF5 object
 type Id int
@@ -35,11 +40,19 @@ type Location interface {
 // fmt.Sprintf. This allows more insighful error reporting and
 // debugging (e.g. `Status(<code>, "while opening file %s", <name>)`
 // will report the file name in the error being returned)
+// The error stack of the library is captured as well (see h5e),
+// and can be inspected using errors.Is and errors.As. As the
+// library keeps the stack per thread, this must be called before
+// releasing the lock taken for the call (see Lock), which keeps the
+// goroutine on the thread of the call.
 func Status(code int, context string, args ...interface{}) error {
 	if code < 0 {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
 		return &status{
 			num:     code,
 			context: fmt.Sprintf(context, args...),
+			stack:   h5e.Current(),
 		}
 	}
 	return nil
@@ -49,13 +62,28 @@ func Status(code int, context string, args ...interface{}) error {
 // as a simple integer, an is considered an error only if this
 // status is negative.
 type status struct {
-	num     int    // The status integer
-	context string // The context in which this status occured
+	num     int       // The status integer
+	context string    // The context in which this status occured
+	stack   h5e.Stack // The error stack of the library
 }
 
 // status implements the error interface, returning the context
 // in which the error was raised, and the HDF5 status returned
 func (self *status) Error() string {
-	return fmt.Sprintf("Error while %s [status: %v]",
-		self.context, self.num)
+	if len(self.stack) == 0 {
+		return fmt.Sprintf("Error while %s [status: %v]",
+			self.context, self.num)
+	}
+	return fmt.Sprintf("Error while %s [status: %v]: %s",
+		self.context, self.num, self.stack)
+}
+
+// The error stack of the library, which allows to inspect the
+// status using errors.Is (e.g. with h5e.ErrNotFound) and errors.As
+// (with an h5e.Stack)
+func (self *status) Unwrap() error {
+	if len(self.stack) == 0 {
+		return nil
+	}
+	return self.stack
 }
@@ -0,0 +1,174 @@
+package core
+
+/*
+#include <hdf5.h>
+*/
+import "C"
+import (
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// The types of the ids handed out by the library
+type IdType int
+
+const (
+	BADID     IdType = C.H5I_BADID       // Invalid (or closed) id
+	FILE      IdType = C.H5I_FILE        // File
+	GROUP     IdType = C.H5I_GROUP       // Group
+	DATATYPE  IdType = C.H5I_DATATYPE    // Datatype
+	DATASPACE IdType = C.H5I_DATASPACE   // Dataspace
+	DATASET   IdType = C.H5I_DATASET     // Dataset
+	ATTRIBUTE IdType = C.H5I_ATTR        // Attribute
+	PROPERTY  IdType = C.H5I_GENPROP_LST // Property list
+)
+
+// The name of the type
+func (t IdType) String() string {
+	switch t {
+	case BADID:
+		return "invalid"
+	case FILE:
+		return "file"
+	case GROUP:
+		return "group"
+	case DATATYPE:
+		return "datatype"
+	case DATASPACE:
+		return "dataspace"
+	case DATASET:
+		return "dataset"
+	case ATTRIBUTE:
+		return "attribute"
+	case PROPERTY:
+		return "property list"
+	}
+	return fmt.Sprintf("type %d", int(t))
+}
+
+// Gets the type of the id, which is BADID if the id is not valid
+// Wraps the H5Iget_type function
+func GetType(id Id) IdType {
+	Lock()
+	defer Unlock()
+	return IdType(C.H5Iget_type(C.hid_t(id)))
+}
+
+// Checks whether the id is valid, i.e. that it was handed out by the
+// library and has not been closed since
+// Wraps the H5Iis_valid function
+func IsValid(id Id) bool {
+	Lock()
+	defer Unlock()
+	return C.H5Iis_valid(C.hid_t(id)) > 0
+}
+
+// Records where each id was created, when tracking is enabled
+var tracker struct {
+	sync.Mutex
+	on     int32         // Whether tracking is enabled (atomic)
+	stacks map[Id][]byte // The stack of the creation of each id
+	pruned int           // The number of ids after the last pruning
+}
+
+// Enables (or disables) the tracking of the ids created by the
+// packages of h5go, which records the stack of the creation of each
+// id so that the ones which are never closed can be reported (see
+// Handles). Tracking is disabled by default, as it slows down the
+// creation of every id. Enabling it discards the previous records.
+func SetTracking(on bool) {
+	tracker.Lock()
+	defer tracker.Unlock()
+	if on && atomic.LoadInt32(&tracker.on) == 0 {
+		tracker.stacks = make(map[Id][]byte)
+		tracker.pruned = 0
+	}
+	if on {
+		atomic.StoreInt32(&tracker.on, 1)
+	} else {
+		atomic.StoreInt32(&tracker.on, 0)
+		tracker.stacks = nil
+	}
+}
+
+// Whether the ids are being tracked
+func Tracking() bool { return atomic.LoadInt32(&tracker.on) != 0 }
+
+// Records the creation of the id when tracking is enabled. This is
+// called by the packages of h5go each time the library hands out a
+// new id, and is a no-op otherwise (or if the id is invalid).
+func Track(id Id) {
+	if id < 0 || atomic.LoadInt32(&tracker.on) == 0 {
+		return
+	}
+	stack := debug.Stack()
+	// The library lock is always taken first, as IsValid takes it
+	Lock()
+	defer Unlock()
+	tracker.Lock()
+	defer tracker.Unlock()
+	if tracker.stacks == nil {
+		return
+	}
+	tracker.stacks[id] = stack
+	// Forgets the ids closed since, so that the records do not grow
+	// without bounds in long-running programs
+	if n := len(tracker.stacks); n > 2*tracker.pruned+1024 {
+		for id := range tracker.stacks {
+			if !IsValid(id) {
+				delete(tracker.stacks, id)
+			}
+		}
+		tracker.pruned = len(tracker.stacks)
+	}
+}
+
+// An id which is still open, along with where it was created
+type Handle struct {
+	Id    Id     // The id
+	Type  IdType // The type of the id
+	Stack string // The stack of the creation of the id
+}
+
+// Textual representation of the handle
+func (h Handle) String() string {
+	return fmt.Sprintf("%s %d, created at:\n%s", h.Type, h.Id, h.Stack)
+}
+
+// The tracked ids which are still open, sorted by type and id. This
+// only reports the ids created while tracking was enabled.
+func Handles() []Handle {
+	Lock()
+	defer Unlock()
+	tracker.Lock()
+	defer tracker.Unlock()
+	out := make([]Handle, 0, len(tracker.stacks))
+	for id, stack := range tracker.stacks {
+		if !IsValid(id) {
+			delete(tracker.stacks, id)
+			continue
+		}
+		out = append(out, Handle{
+			Id:    id,
+			Type:  GetType(id),
+			Stack: string(stack),
+		})
+	}
+	sort.Sort(byType(out))
+	return out
+}
+
+// Sorts handles by type, then by id
+type byType []Handle
+
+func (h byType) Len() int      { return len(h) }
+func (h byType) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h byType) Less(i, j int) bool {
+	if h[i].Type != h[j].Type {
+		return h[i].Type < h[j].Type
+	}
+	return h[i].Id < h[j].Id
+}
@@ -0,0 +1,306 @@
+package h5go
+
+import (
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5a"
+	"github.com/valoox/h5go/h5d"
+	"github.com/valoox/h5go/h5s"
+	"github.com/valoox/h5go/h5t"
+)
+
+// An option modifying the creation properties of a dataset
+type Option func(h5d.Crt) error
+
+// Stores the dataset in chunks of the given dimensions
+func Chunks(dims ...int) Option {
+	return func(c h5d.Crt) error { return c.SetChunk(dims) }
+}
+
+// Compresses the dataset using the deflate (gzip) filter, with the
+// given level (0-9). The dataset must be chunked
+func Deflate(level uint) Option {
+	return func(c h5d.Crt) error { return c.SetDeflate(level) }
+}
+
+// Shuffles the bytes of the elements before compressing them
+// (this must come before the compression filter)
+func Shuffle() Option {
+	return func(c h5d.Crt) error { return c.SetShuffle() }
+}
+
+// Sets the value of the elements which are never written. The value
+// must be a single element, converted to the type of the dataset
+func Fill(value interface{}) Option {
+	return func(c h5d.Crt) error {
+		buf, err := newBuffer(value, nil)
+		if err != nil {
+			return err
+		}
+		defer buf.Close()
+		return c.SetFillValue(buf)
+	}
+}
+
+// Maps elements of the (virtual) dataset to the elements of a source
+// dataset, which can live in another file (see h5d.Crt.SetVirtual)
+func Virtual(vspace h5s.Dataspace, srcFile string, srcDataset core.Path,
+	srcSpace h5s.Dataspace) Option {
+	return func(c h5d.Crt) error {
+		return c.SetVirtual(vspace, srcFile, srcDataset, srcSpace)
+	}
+}
+
+// Creates the dataset creation properties, from the defaults of the
+// location amended with the options. The result should be closed
+func (l *loc) creation(opts ...Option) (h5d.Crt, error) {
+	crt, err := l.dcreate.Copy()
+	if err != nil {
+		return crt, err
+	}
+	for _, opt := range opts {
+		if err := opt(crt); err != nil {
+			crt.Close()
+			return crt, err
+		}
+	}
+	return crt, nil
+}
+
+// Wraps an h5d.Dataset handle, and remembers where it lives as well
+// as its type and shape
+type Dataset struct {
+	h5d.Dataset              // The embedded Dataset handle
+	path        core.Path    // The path to the dataset in the file
+	in          *File        // The file this belongs to
+	dtype       h5t.Datatype // The datatype of the dataset
+	dims        []int        // The current shape of the dataset
+}
+
+// The path to the dataset in its file
+func (d *Dataset) Path() core.Path { return d.path }
+
+// The file containing the dataset
+func (d *Dataset) File() *File { return d.in }
+
+// The datatype of the dataset. This is owned by the dataset, and
+// closed with it
+func (d *Dataset) Datatype() h5t.Datatype { return d.dtype }
+
+// The current dimensions of the dataset
+func (d *Dataset) Dims() []int { return d.dims }
+
+// Changes the dimensions of the dataset (see h5d.Dataset.SetDims)
+func (d *Dataset) SetDims(dims []int) error {
+	if err := d.Dataset.SetDims(dims); err != nil {
+		return err
+	}
+	d.dims = append([]int(nil), dims...)
+	return nil
+}
+
+// Reloads the metadata of the dataset, updating its dimensions, when
+// it is read while being written by another process (see
+// h5d.Dataset.Refresh)
+func (d *Dataset) Refresh() error {
+	if err := d.Dataset.Refresh(); err != nil {
+		return err
+	}
+	S, err := d.Shape()
+	if err != nil {
+		return err
+	}
+	defer S.Close()
+	dims, _, err := S.GetDims()
+	if err != nil {
+		return err
+	}
+	d.dims = dims
+	return nil
+}
+
+// Sets an attribute on the dataset (see loc.SetAttr)
+func (d *Dataset) SetAttr(name string, value interface{}) error {
+	return setattr(d.Dataset, d.in, name, value)
+}
+
+// Reads an attribute of the dataset (see loc.Attr)
+func (d *Dataset) Attr(name string, out interface{}) error {
+	return getattr(d.Dataset, d.in, name, out)
+}
+
+// Checks whether the dataset has an attribute with this name
+func (d *Dataset) HasAttr(name string) (bool, error) {
+	return h5a.Exists(d.Dataset, name)
+}
+
+// Deletes the attribute of the dataset
+func (d *Dataset) DelAttr(name string) error {
+	return h5a.Delete(d.Dataset, name)
+}
+
+// Closes the dataset, releasing its datatype
+func (d *Dataset) Close() error {
+	err := d.dtype.Close()
+	if cerr := d.Dataset.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Wraps the dataset handle, loading its type and shape
+func (l *loc) wrap(did h5d.Dataset, path core.Path) (*Dataset, error) {
+	out := &Dataset{
+		Dataset: did,
+		path:    core.Join(l.at, path),
+		in:      l.in,
+		dtype:   -1,
+	}
+	var err error
+	if out.dtype, err = did.Type(); err != nil {
+		did.Close()
+		return nil, err
+	}
+	S, err := did.Shape()
+	if err != nil {
+		out.Close()
+		return nil, err
+	}
+	defer S.Close()
+	if out.dims, _, err = S.GetDims(); err != nil {
+		out.Close()
+		return nil, err
+	}
+	return out, nil
+}
+
+// Creates a new dataset at this location, holding the provided data.
+// The datatype is inferred using h5t.Parse, and the shape from the
+// (possibly nested) slices and arrays of the data.
+// The dataset is created with the creation and access defaults of
+// the location, which can be amended with the provided options
+func (l *loc) NewDataset(path core.Path, data interface{},
+	opts ...Option) (*Dataset, error) {
+	buf, err := newBuffer(data, l.where)
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Close()
+	T, err := buf.Type()
+	if err != nil {
+		return nil, err
+	}
+	defer T.Close()
+	S, err := buf.Shape()
+	if err != nil {
+		return nil, err
+	}
+	defer S.Close()
+	crt, err := l.creation(opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer crt.Close()
+	did, err := h5d.Create(l.where, path, T, S, l.lcreate, crt,
+		l.daccess)
+	if err != nil {
+		return nil, err
+	}
+	if count(buf.dims) > 0 {
+		if err := did.Write(buf, h5s.ALL,
+			h5d.DefaultXfer); err != nil {
+			did.Close()
+			return nil, err
+		}
+	}
+	return l.wrap(did, path)
+}
+
+// Creates a new empty dataset at this location, of the given type
+// and dimensions. The maximum dimensions can be nil (the dataset then
+// cannot be resized), or -1 for the dimensions which are unlimited,
+// in which case the dataset must be chunked (see h5s.CreateSimple).
+// The dataset is created with the creation and access defaults of
+// the location, which can be amended with the provided options
+func (l *loc) CreateDataset(path core.Path, dtype h5t.Datatype,
+	dims, maxs []int, opts ...Option) (*Dataset, error) {
+	var S h5s.Dataspace
+	var err error
+	if len(dims) == 0 {
+		S, err = h5s.CreateScalar()
+	} else {
+		S, err = h5s.CreateSimple(dims, maxs)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer S.Close()
+	crt, err := l.creation(opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer crt.Close()
+	did, err := h5d.Create(l.where, path, dtype, S, l.lcreate, crt,
+		l.daccess)
+	if err != nil {
+		return nil, err
+	}
+	return l.wrap(did, path)
+}
+
+// Opens the existing dataset at the given path from this location
+func (l *loc) OpenDataset(path core.Path) (*Dataset, error) {
+	did, err := h5d.Open(l.where, path, l.daccess)
+	if err != nil {
+		return nil, err
+	}
+	return l.wrap(did, path)
+}
+
+// Reads the entire dataset into the value pointed by `dst`
+func readAll(ds h5d.Dataset, ctxt core.Location, dst interface{}) error {
+	T, err := ds.Type()
+	if err != nil {
+		return err
+	}
+	defer T.Close()
+	S, err := ds.Shape()
+	if err != nil {
+		return err
+	}
+	defer S.Close()
+	dims, _, err := S.GetDims()
+	if err != nil {
+		return err
+	}
+	return readInto(T, dims, ctxt, func(buf h5d.OBuffer) error {
+		return ds.Read(buf, h5s.ALL, h5d.DefaultXfer)
+	}, dst)
+}
+
+// Reads the entire dataset into the value pointed by `dst`, which
+// must be a pointer to a scalar, a slice or an array (possibly
+// nested) of a type compatible with the datatype of the dataset.
+// Slices are allocated to the shape of the dataset, so that the
+// caller does not need to size them beforehand; multi-dimensional
+// datasets can also be read into a flat slice.
+func ReadAll(ds h5d.Dataset, dst interface{}) error {
+	return readAll(ds, nil, dst)
+}
+
+// Reads the entire dataset into the value pointed by `dst`
+// (see ReadAll)
+func (d *Dataset) ReadAll(dst interface{}) error {
+	return readAll(d.Dataset, d.in, dst)
+}
+
+// Writes the entire dataset from the data, which must hold as many
+// elements as the dataset (its shape is otherwise ignored, so that a
+// multi-dimensional dataset can also be written from a flat slice)
+func (d *Dataset) WriteAll(data interface{}) error {
+	sel, err := d.Select()
+	if err != nil {
+		return err
+	}
+	defer sel.Close()
+	return sel.Write(data)
+}
@@ -0,0 +1,143 @@
+package h5go
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+import (
+	"github.com/valoox/h5go/h5d"
+	"github.com/valoox/h5go/h5z"
+)
+
+// Creates a dataset from a Go value and reopens it
+func TestNewDataset(t *testing.T) {
+	const testfile = "./dataset.h5"
+	f, err := Create(testfile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(testfile)
+	defer f.Close()
+	data := [][]int32{{1, 2, 3}, {4, 5, 6}}
+	ds, err := f.NewDataset("ints", data, Chunks(1, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dims := ds.Dims(); len(dims) != 2 || dims[0] != 2 || dims[1] != 3 {
+		t.Fatalf("Wrong dimensions: %v", dims)
+	}
+	if err := ds.SetAttr("units", "counts"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.Close(); err != nil {
+		t.Fatal(err)
+	}
+	ds, err = f.OpenDataset("ints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ds.Close()
+	if ds.Path() != "ints" || ds.File() != f {
+		t.Fatalf("Wrong location: %s", ds.Path())
+	}
+	var units string
+	if err := ds.Attr("units", &units); err != nil {
+		t.Fatal(err)
+	} else if units != "counts" {
+		t.Fatalf("Expected counts, got %q", units)
+	}
+}
+
+// Reads entire datasets into freshly allocated Go values
+func TestReadAll(t *testing.T) {
+	const testfile = "./readall.h5"
+	f, err := Create(testfile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(testfile)
+	defer f.Close()
+	ds, err := f.NewDataset("floats", [2][3]float64{{1, 2, 3}, {4, 5, 6}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ds.Close()
+	var nested [][]float64
+	if err := ds.ReadAll(&nested); err != nil {
+		t.Fatal(err)
+	} else if len(nested) != 2 || nested[1][0] != 4 {
+		t.Fatalf("Wrong data read back: %v", nested)
+	}
+	var arr [2][3]float32
+	if err := ReadAll(ds.Dataset, &arr); err != nil {
+		t.Fatal(err)
+	} else if arr[0][2] != 3 {
+		t.Fatalf("Wrong data read back: %v", arr)
+	}
+	var strs []string
+	if err := ds.ReadAll(&strs); err == nil {
+		t.Fatal("Expected an error reading floats into strings")
+	}
+}
+
+// Creates a compressed dataset and lists its filters
+func TestFilters(t *testing.T) {
+	const testfile = "./filters.h5"
+	f, err := Create(testfile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(testfile)
+	defer f.Close()
+	data := make([]float64, 1000)
+	ds, err := f.NewDataset("zipped", data,
+		Chunks(100), Shuffle(), Deflate(6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ds.Close()
+	crt, err := ds.Creation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer crt.Close()
+	filters, err := crt.GetFilters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 2 || filters[0].Id != h5z.SHUFFLE ||
+		filters[1].Id != h5z.DEFLATE {
+		t.Fatalf("Wrong filters: %v", filters)
+	}
+}
+
+// Adds optional filters which are not available, with many values
+func TestOptionalFilter(t *testing.T) {
+	crt, err := h5d.Creation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer crt.Close()
+	const missing = h5z.Filter(333)
+	if err := crt.SetFilter(missing, h5z.MANDATORY, nil); err == nil {
+		t.Fatal("Expected an error adding a missing mandatory filter")
+	}
+	values := make([]uint, 20)
+	for i := range values {
+		values[i] = uint(i + 1)
+	}
+	if err := crt.SetFilter(missing, h5z.OPTIONAL, values); err != nil {
+		t.Fatal(err)
+	}
+	filters, err := crt.GetFilters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 1 || filters[0].Id != missing {
+		t.Fatalf("Wrong filters: %v", filters)
+	}
+	if !reflect.DeepEqual(filters[0].CdValues, values) {
+		t.Fatalf("Expected values %v, got %v", values, filters[0].CdValues)
+	}
+}
@@ -0,0 +1,120 @@
+package h5go
+
+import (
+	"fmt"
+
+	"github.com/valoox/h5go/h5f"
+)
+
+// The options for opening or creating a file (see OpenFile and
+// CreateFile). The zero value uses the default properties.
+type FileOptions struct {
+	// The driver through which the file is accessed, or nil to keep
+	// the driver of the access properties (sec2 by default)
+	Driver Driver
+	// The access properties, to which the driver is applied
+	Access h5f.Acc
+	// The creation properties, only used when creating a file
+	Create h5f.Crt
+	// Uses the latest version of the file format, which is required
+	// for single-writer/multiple-readers (SWMR) access
+	Latest bool
+	// Opens the file for single-writer/multiple-readers access: for
+	// reading if it is opened read-only, and for writing otherwise.
+	// New files are rather switched to SWMR writing once all their
+	// objects are created (see h5f.File.StartSWMRWrite).
+	SWMR bool
+}
+
+// Selects the driver (with its parameters) on the access properties
+type Driver func(h5f.Acc) error
+
+// Performs unbuffered POSIX I/O (the default driver)
+func Sec2() Driver {
+	return func(a h5f.Acc) error { return a.SetSec2() }
+}
+
+// Performs buffered I/O through the C standard library
+func Stdio() Driver {
+	return func(a h5f.Acc) error { return a.SetStdio() }
+}
+
+// Keeps the entire file in memory, growing by `increment` bytes at a
+// time. If `backing` is set, the file is written to the disk when
+// closed (see h5f.Acc.SetCore)
+func Core(increment int, backing bool) Driver {
+	return func(a h5f.Acc) error { return a.SetCore(increment, backing) }
+}
+
+// Splits the file into members of `size` bytes. The path of the file
+// must contain an integer pattern, such as "data-%d.h5"
+// (see h5f.Acc.SetFamily)
+func Family(size int) Driver {
+	return func(a h5f.Acc) error {
+		return a.SetFamily(size, h5f.DefaultAccess)
+	}
+}
+
+// Stores the metadata and the raw data in two files, named after the
+// path of the file followed by the extensions (see h5f.Acc.SetSplit)
+func Split(metaExt, rawExt string) Driver {
+	return func(a h5f.Acc) error {
+		return a.SetSplit(metaExt, h5f.DefaultAccess,
+			rawExt, h5f.DefaultAccess)
+	}
+}
+
+// Logs the operations selected by the flags to the given file, or to
+// the standard error if it is empty (see h5f.Acc.SetLog)
+func Log(logfile string, flags h5f.LogFlag, bufsize int) Driver {
+	return func(a h5f.Acc) error { return a.SetLog(logfile, flags, bufsize) }
+}
+
+// The options used by Open and Create
+func legacy() *FileOptions {
+	return &FileOptions{Access: FileAccess, Create: FileCreate}
+}
+
+// Checks that the options can be used for a file in memory, which
+// is always held by the core driver and has no other readers
+func (o *FileOptions) inMemory() error {
+	if o.Driver != nil {
+		return fmt.Errorf("Cannot select a driver for a file in memory")
+	}
+	if o.SWMR {
+		return fmt.Errorf("Cannot use SWMR access on a file in memory")
+	}
+	return nil
+}
+
+// Creates the access properties with the driver and format applied,
+// along with the function releasing them
+func (o *FileOptions) access() (h5f.Acc, func(), error) {
+	if o.Driver == nil && !o.Latest {
+		return o.Access, func() {}, nil
+	}
+	var acc h5f.Acc
+	var err error
+	if o.Access == h5f.DefaultAccess {
+		acc, err = h5f.Access()
+	} else {
+		acc, err = o.Access.Copy()
+	}
+	if err != nil {
+		return acc, nil, err
+	}
+	if o.Driver != nil {
+		if err := o.Driver(acc); err != nil {
+			acc.Close()
+			return acc, nil, err
+		}
+	}
+	if o.Latest {
+		if err := acc.SetLibverBounds(h5f.LIBVER_LATEST,
+			h5f.LIBVER_LATEST); err != nil {
+			acc.Close()
+			return acc, nil, err
+		}
+	}
+	return acc, func() { acc.Close() }, nil
+}
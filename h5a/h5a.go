@@ -0,0 +1,300 @@
+// This wraps the H5A* family of functions, for creating and
+// manipulating the attributes attached to the objects of a file
+package h5a
+
+/*
+#cgo LDFLAGS: -lhdf5
+#include <stdlib.h>
+#include <hdf5.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+import (
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5d"
+	"github.com/valoox/h5go/h5o"
+	"github.com/valoox/h5go/h5p"
+	"github.com/valoox/h5go/h5s"
+	"github.com/valoox/h5go/h5t"
+)
+
+// Default attribute creation property list
+const DefaultCreate = Crt(h5p.Default)
+
+// Creates a new attribute creation property list
+func Creation() (Crt, error) {
+	id, err := h5p.Create(h5p.ATTRIBUTE_CREATE)
+	return Crt(id), err
+}
+
+// Attribute creation property list
+type Crt h5p.Property
+
+// The Property list ID
+func (self Crt) Id() h5p.Property { return h5p.Property(self) }
+
+// Copies the property list
+func (self Crt) Copy() (Crt, error) {
+	id, err := h5p.Copy(self.Id())
+	return Crt(id), err
+}
+
+// The class of the property list
+func (self Crt) Class() h5p.Class { return h5p.ATTRIBUTE_CREATE }
+
+// Disposes of the resource
+func (self Crt) Close() error { return h5p.Close(self.Id()) }
+
+// Sets the encoding used for the name of the attribute, either
+// UTF-8 (utf8 = true) or ASCII (utf8 = false)
+// Wraps the H5Pset_char_encoding function
+func (self Crt) SetEncoding(utf8 bool) error {
+	core.Lock()
+	defer core.Unlock()
+	cset := C.H5T_CSET_ASCII
+	if utf8 {
+		cset = C.H5T_CSET_UTF8
+	}
+	return core.Status(int(C.H5Pset_char_encoding(C.hid_t(self),
+		C.H5T_cset_t(cset))), "setting attribute name encoding")
+}
+
+// Gets whether the name of the attribute is encoded in UTF-8
+// Wraps the H5Pget_char_encoding function
+func (self Crt) GetEncoding() (utf8 bool, err error) {
+	core.Lock()
+	defer core.Unlock()
+	var cset C.H5T_cset_t
+	err = core.Status(int(C.H5Pget_char_encoding(C.hid_t(self),
+		&cset)), "getting attribute name encoding")
+	return cset == C.H5T_CSET_UTF8, err
+}
+
+// Represents an Id specifically for attributes
+type Attribute core.Id
+
+// The HDF5 Id for this attribute
+func (a Attribute) Id() core.Id { return core.Id(a) }
+
+// Closes the attribute
+// Wraps the H5Aclose function
+func (a Attribute) Close() error {
+	core.Lock()
+	defer core.Unlock()
+	return core.Status(int(C.H5Aclose(C.hid_t(a))),
+		"closing attribute")
+}
+
+// The dataspace of the attribute
+// Wraps the H5Aget_space function
+func (a Attribute) Shape() (h5s.Dataspace, error) {
+	core.Lock()
+	defer core.Unlock()
+	out := h5s.Dataspace(C.H5Aget_space(C.hid_t(a)))
+	core.Track(core.Id(out))
+	return out, core.Status(int(out),
+		"getting dataspace of attribute %v", a)
+}
+
+// The datatype of the attribute
+// Wraps the H5Aget_type function
+func (a Attribute) Type() (h5t.Datatype, error) {
+	core.Lock()
+	defer core.Unlock()
+	out := h5t.Datatype(C.H5Aget_type(C.hid_t(a)))
+	core.Track(core.Id(out))
+	return out, core.Status(int(out),
+		"getting datatype of attribute %v", a)
+}
+
+// The name of the attribute
+// Wraps the H5Aget_name function
+func (a Attribute) Name() (string, error) {
+	core.Lock()
+	defer core.Unlock()
+	sze := C.H5Aget_name(C.hid_t(a), 0, nil)
+	if err := core.Status(int(sze),
+		"getting attribute name"); err != nil {
+		return "", err
+	}
+	out := make([]C.char, int(sze)+1)
+	if err := core.Status(int(C.H5Aget_name(C.hid_t(a),
+		C.size_t(len(out)), &out[0])),
+		"getting attribute name"); err != nil {
+		return "", err
+	}
+	return C.GoString(&out[0]), nil
+}
+
+// Writes the content of the buffer in the attribute.
+// Attributes are always written entirely, so the shape of the
+// buffer is ignored: it must simply hold enough elements.
+// Wraps the H5Awrite function
+func (a Attribute) Write(data h5d.IBuffer) error {
+	core.Lock()
+	defer core.Unlock()
+	T, err := data.Type()
+	if err != nil {
+		return err
+	}
+	defer T.Close()
+	return core.Status(int(C.H5Awrite(C.hid_t(a),
+		C.hid_t(T),
+		data.ReadPtr())),
+		"writing attribute")
+}
+
+// Reads the attribute into the provided buffer
+// Wraps the H5Aread function
+func (a Attribute) Read(data h5d.OBuffer) error {
+	core.Lock()
+	defer core.Unlock()
+	T, err := data.Type()
+	if err != nil {
+		return err
+	}
+	defer T.Close()
+	return core.Status(int(C.H5Aread(C.hid_t(a),
+		C.hid_t(T),
+		data.WritePtr())),
+		"reading attribute")
+}
+
+// Describes an attribute
+type Info struct {
+	// Whether the creation order is valid
+	CorderValid bool
+	// The creation order of the attribute
+	Corder int
+	// Whether the name of the attribute is UTF-8 encoded
+	UTF8 bool
+	// The size of the data, in bytes
+	Size int
+}
+
+// Gets the description of the attribute
+// Wraps the H5Aget_info function
+func (a Attribute) Info() (Info, error) {
+	core.Lock()
+	defer core.Unlock()
+	var info C.H5A_info_t
+	if err := core.Status(int(C.H5Aget_info(C.hid_t(a), &info)),
+		"getting attribute info"); err != nil {
+		return Info{}, err
+	}
+	return Info{
+		CorderValid: bool(info.corder_valid),
+		Corder:      int(info.corder),
+		UTF8:        info.cset == C.H5T_CSET_UTF8,
+		Size:        int(info.data_size),
+	}, nil
+}
+
+// Returns the attribute, raising an error if the id is negative
+func try(id Attribute, context string, args ...interface{}) (Attribute, error) {
+	core.Track(core.Id(id))
+	return id, core.Status(int(id), fmt.Sprintf(context, args...))
+}
+
+// Creates a new attribute attached to the object
+// Wraps the H5Acreate2 function
+func Create(at core.Object, name string, dtype h5t.Datatype,
+	dspace h5s.Dataspace, c Crt) (Attribute, error) {
+	core.Lock()
+	defer core.Unlock()
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	return try(Attribute(C.H5Acreate2(C.hid_t(at.Id()),
+		cname, C.hid_t(dtype), C.hid_t(dspace),
+		C.hid_t(c), C.H5P_DEFAULT)),
+		"creating attribute %s", name)
+}
+
+// Opens an existing attribute of the object
+// Wraps the H5Aopen function
+func Open(at core.Object, name string) (Attribute, error) {
+	core.Lock()
+	defer core.Unlock()
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	return try(Attribute(C.H5Aopen(C.hid_t(at.Id()),
+		cname, C.H5P_DEFAULT)),
+		"opening attribute %s", name)
+}
+
+// Deletes the attribute from the object
+// Wraps the H5Adelete function
+func Delete(at core.Object, name string) error {
+	core.Lock()
+	defer core.Unlock()
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	return core.Status(int(C.H5Adelete(C.hid_t(at.Id()), cname)),
+		"deleting attribute %s", name)
+}
+
+// Renames an attribute of the object
+// Wraps the H5Arename function
+func Rename(at core.Object, old, name string) error {
+	core.Lock()
+	defer core.Unlock()
+	cold, cname := C.CString(old), C.CString(name)
+	defer C.free(unsafe.Pointer(cold))
+	defer C.free(unsafe.Pointer(cname))
+	return core.Status(int(C.H5Arename(C.hid_t(at.Id()),
+		cold, cname)),
+		"renaming attribute %s to %s", old, name)
+}
+
+// Checks whether the object has an attribute with this name
+// Wraps the H5Aexists function
+func Exists(at core.Object, name string) (bool, error) {
+	core.Lock()
+	defer core.Unlock()
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	res := C.H5Aexists(C.hid_t(at.Id()), cname)
+	return res > 0, core.Status(int(res),
+		"checking existence of attribute %s", name)
+}
+
+// Gets the names of the attributes of the object, following the
+// index in the given order. Ordering by creation requires the
+// creation order of the attributes to be tracked.
+// Wraps the H5Aget_name_by_idx function
+func Names(at core.Object, idx core.Index, order core.Order) ([]string, error) {
+	core.Lock()
+	defer core.Unlock()
+	info, err := h5o.GetInfo(at)
+	if err != nil {
+		return nil, err
+	}
+	dot := C.CString(".")
+	defer C.free(unsafe.Pointer(dot))
+	names := make([]string, info.NumAttrs)
+	for i := range names {
+		sze := C.H5Aget_name_by_idx(C.hid_t(at.Id()), dot,
+			C.H5_index_t(idx), C.H5_iter_order_t(order),
+			C.hsize_t(i), nil, 0, C.H5P_DEFAULT)
+		if err := core.Status(int(sze),
+			"getting name of attribute %v", i); err != nil {
+			return nil, err
+		}
+		out := make([]C.char, int(sze)+1)
+		if err := core.Status(int(C.H5Aget_name_by_idx(
+			C.hid_t(at.Id()), dot,
+			C.H5_index_t(idx), C.H5_iter_order_t(order),
+			C.hsize_t(i), &out[0], C.size_t(len(out)),
+			C.H5P_DEFAULT)),
+			"getting name of attribute %v", i); err != nil {
+			return nil, err
+		}
+		names[i] = C.GoString(&out[0])
+	}
+	return names, nil
+}
@@ -0,0 +1,28 @@
+#include <stdint.h>
+#include <hdf5.h>
+#include "_cgo_export.h"
+
+#if H5_VERSION_GE(1, 14, 0)
+
+// Forwards the chunks to the Go callback
+static int chunk_cb(const hsize_t *offset, unsigned filters, haddr_t addr,
+		    hsize_t size, void *data) {
+  return goChunkCallback((hsize_t *)offset, filters, addr, size,
+			 (uintptr_t)data);
+}
+
+// Iterates over the chunks allocated in the file
+herr_t h5d_chunk_iter(hid_t ds, hid_t dxpl, uintptr_t h) {
+  return H5Dchunk_iter(ds, dxpl, chunk_cb, (void *)h);
+}
+
+int h5d_chunk_iter_supported(void) { return 1; }
+
+#else
+
+// H5Dchunk_iter only exists from HDF5 1.14
+herr_t h5d_chunk_iter(hid_t ds, hid_t dxpl, uintptr_t h) { return -1; }
+
+int h5d_chunk_iter_supported(void) { return 0; }
+
+#endif
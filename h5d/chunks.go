@@ -0,0 +1,263 @@
+package h5d
+
+/*
+#include <stdint.h>
+#include <hdf5.h>
+
+herr_t h5d_chunk_iter(hid_t, hid_t, uintptr_t);
+int h5d_chunk_iter_supported(void);
+*/
+import "C"
+import (
+	"fmt"
+	"runtime/cgo"
+	"unsafe"
+)
+
+import (
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5s"
+)
+
+// The address of a chunk which is not allocated in the file
+const UNDEF_ADDR = ^uint64(0)
+
+// Describes a chunk of a dataset, as stored in the file
+type ChunkInfo struct {
+	Offset  []uint // The coordinates of the first element of the chunk
+	Filters uint32 // The mask of the filters skipped (see WriteChunk)
+	Size    int    // The size of the chunk in the file, in bytes
+	Addr    uint64 // The address of the chunk in the file
+}
+
+// Whether the chunk is allocated in the file. Chunks which were never
+// written are not, and read as the fill value.
+func (c ChunkInfo) Allocated() bool { return c.Addr != UNDEF_ADDR }
+
+// The C coordinates of the chunk starting at the offset, which must
+// have as many coordinates as the dataset has dimensions
+func (d Dataset) coffset(offset []uint) ([]C.hsize_t, error) {
+	rank, err := d.rank()
+	if err != nil {
+		return nil, err
+	}
+	if len(offset) != rank {
+		return nil, fmt.Errorf("Invalid chunk offset %v: expecting %v coordinates", offset, rank)
+	}
+	out := make([]C.hsize_t, rank+1)
+	for i, x := range offset {
+		out[i] = C.hsize_t(x)
+	}
+	return out, nil
+}
+
+// The rank of the dataset
+func (d Dataset) rank() (int, error) {
+	space, err := d.Shape()
+	if err != nil {
+		return 0, err
+	}
+	defer space.Close()
+	return space.GetRank()
+}
+
+// Writes a raw chunk, bypassing the filter pipeline: the data must be
+// already filtered (e.g. compressed), such as read by ReadChunk. The
+// offset gives the coordinates of the first element of the chunk, and
+// bit i of the filter mask is set if the i-th filter of the pipeline
+// was skipped (0 when all the filters were applied).
+// Wraps the H5Dwrite_chunk function
+func (d Dataset) WriteChunk(offset []uint, filters uint32, data []byte,
+	xfr Xfer) error {
+	core.Lock()
+	defer core.Unlock()
+	coffset, err := d.coffset(offset)
+	if err != nil {
+		return err
+	}
+	var ptr unsafe.Pointer
+	if len(data) > 0 {
+		ptr = unsafe.Pointer(&data[0])
+	}
+	return core.Status(int(C.H5Dwrite_chunk(C.hid_t(d), C.hid_t(xfr),
+		C.uint32_t(filters), &coffset[0], C.size_t(len(data)),
+		ptr)), "writing chunk %v", offset)
+}
+
+// Reads a raw chunk, bypassing the filter pipeline: the data is
+// returned as stored in the file (e.g. compressed), along with the
+// mask of the filters skipped (see WriteChunk). The chunk must be
+// allocated.
+// Wraps the H5Dread_chunk function
+func (d Dataset) ReadChunk(offset []uint, xfr Xfer) ([]byte, uint32, error) {
+	core.Lock()
+	defer core.Unlock()
+	coffset, err := d.coffset(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	size, err := d.GetChunkStorageSize(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if size == 0 {
+		return nil, 0, fmt.Errorf("Chunk %v is not allocated", offset)
+	}
+	out := make([]byte, size)
+	var filters C.uint32_t
+	err = core.Status(int(C.H5Dread_chunk(C.hid_t(d), C.hid_t(xfr),
+		&coffset[0], &filters, unsafe.Pointer(&out[0]))),
+		"reading chunk %v", offset)
+	return out, uint32(filters), err
+}
+
+// Gets the size in the file of the chunk starting at the given
+// offset, in bytes, which is 0 if it is not allocated
+// Wraps the H5Dget_chunk_storage_size function
+func (d Dataset) GetChunkStorageSize(offset []uint) (int, error) {
+	core.Lock()
+	defer core.Unlock()
+	coffset, err := d.coffset(offset)
+	if err != nil {
+		return 0, err
+	}
+	var size C.hsize_t
+	err = core.Status(int(C.H5Dget_chunk_storage_size(C.hid_t(d),
+		&coffset[0], &size)), "getting size of chunk %v", offset)
+	return int(size), err
+}
+
+// Gets the number of chunks allocated in the file. The selection is
+// meant to restrict the count to the chunks it intersects, but is
+// currently ignored by the library: it should be h5s.ALL.
+// Wraps the H5Dget_num_chunks function
+func (d Dataset) GetNumChunks(selection h5s.Dataspace) (int, error) {
+	core.Lock()
+	defer core.Unlock()
+	var n C.hsize_t
+	err := core.Status(int(C.H5Dget_num_chunks(C.hid_t(d),
+		C.hid_t(selection), &n)), "getting number of chunks")
+	return int(n), err
+}
+
+// Gets the description of the i-th chunk allocated in the file (see
+// GetNumChunks, for the selection)
+// Wraps the H5Dget_chunk_info function
+func (d Dataset) GetChunkInfo(selection h5s.Dataspace, i int) (ChunkInfo, error) {
+	core.Lock()
+	defer core.Unlock()
+	rank, err := d.rank()
+	if err != nil {
+		return ChunkInfo{}, err
+	}
+	offset := make([]C.hsize_t, rank+1)
+	var filters C.unsigned
+	var addr C.haddr_t
+	var size C.hsize_t
+	if err := core.Status(int(C.H5Dget_chunk_info(C.hid_t(d),
+		C.hid_t(selection), C.hsize_t(i), &offset[0], &filters, &addr,
+		&size)), "getting chunk %v", i); err != nil {
+		return ChunkInfo{}, err
+	}
+	return newChunkInfo(offset[:rank], filters, addr, size), nil
+}
+
+// Gets the description of the chunk starting at the given offset,
+// which is returned (with an undefined address) even if the chunk is
+// not allocated
+// Wraps the H5Dget_chunk_info_by_coord function
+func (d Dataset) GetChunkInfoByCoord(offset []uint) (ChunkInfo, error) {
+	core.Lock()
+	defer core.Unlock()
+	coffset, err := d.coffset(offset)
+	if err != nil {
+		return ChunkInfo{}, err
+	}
+	var filters C.unsigned
+	var addr C.haddr_t
+	var size C.hsize_t
+	if err := core.Status(int(C.H5Dget_chunk_info_by_coord(C.hid_t(d),
+		&coffset[0], &filters, &addr, &size)),
+		"getting chunk %v", offset); err != nil {
+		return ChunkInfo{}, err
+	}
+	return ChunkInfo{
+		Offset:  append([]uint(nil), offset...),
+		Filters: uint32(filters),
+		Size:    int(size),
+		Addr:    uint64(addr),
+	}, nil
+}
+
+// Describes a chunk from its C description
+func newChunkInfo(offset []C.hsize_t, filters C.unsigned, addr C.haddr_t,
+	size C.hsize_t) ChunkInfo {
+	out := ChunkInfo{
+		Offset:  make([]uint, len(offset)),
+		Filters: uint32(filters),
+		Size:    int(size),
+		Addr:    uint64(addr),
+	}
+	for i, x := range offset {
+		out.Offset[i] = uint(x)
+	}
+	return out
+}
+
+// The function called for each chunk allocated in the file. Returning
+// core.Stop ends the iteration early, while any other error aborts it
+// and is returned by the iteration.
+type ChunkFunc func(info ChunkInfo) error
+
+// The state of an iteration over chunks, shared with the C callback
+type chunkIteration struct {
+	fn   ChunkFunc // The Go callback
+	rank int       // The rank of the dataset
+	err  error     // The error returned by the callback, if any
+}
+
+// Called by the library for each chunk
+//
+//export goChunkCallback
+func goChunkCallback(offset *C.hsize_t, filters C.unsigned, addr C.haddr_t,
+	size C.hsize_t, h C.uintptr_t) C.int {
+	it := cgo.Handle(h).Value().(*chunkIteration)
+	coords := unsafe.Slice(offset, it.rank)
+	switch err := it.fn(newChunkInfo(coords, filters, addr, size)); err {
+	case nil:
+		return 0
+	case core.Stop:
+		return 1
+	default:
+		it.err = err
+		return -1
+	}
+}
+
+// Whether IterChunks is supported, which requires HDF5 1.14
+func IterChunksSupported() bool { return C.h5d_chunk_iter_supported() != 0 }
+
+// Calls the function on each of the chunks allocated in the file,
+// in the order of their index. This is faster than getting them one
+// by one with GetChunkInfo, but requires HDF5 1.14 (see
+// IterChunksSupported).
+// Wraps the H5Dchunk_iter function
+func (d Dataset) IterChunks(xfr Xfer, fn ChunkFunc) error {
+	core.Lock()
+	defer core.Unlock()
+	if !IterChunksSupported() {
+		return fmt.Errorf("Iterating over chunks requires HDF5 1.14")
+	}
+	rank, err := d.rank()
+	if err != nil {
+		return err
+	}
+	it := &chunkIteration{fn: fn, rank: rank}
+	h := cgo.NewHandle(it)
+	defer h.Delete()
+	res := C.h5d_chunk_iter(C.hid_t(d), C.hid_t(xfr), C.uintptr_t(h))
+	if it.err != nil {
+		return it.err
+	}
+	return core.Status(int(res), "iterating over chunks of dataset %v", d)
+}
@@ -0,0 +1,241 @@
+package h5d
+
+import (
+	"context"
+	"fmt"
+)
+
+import (
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5s"
+)
+
+// Reports the progress of a transfer, as the number of elements
+// transferred so far out of the total number of elements selected
+type Progress func(done, total int)
+
+// The approximate number of bytes transferred at once by ReadContext
+// and WriteContext. The pieces always span whole chunks.
+var PieceSize = 4 * core.MB
+
+// A buffer seen through another memory dataspace
+type opiece struct {
+	OBuffer
+	mem h5s.Dataspace
+}
+
+// The memory dataspace, copied as it is closed after use
+func (p opiece) Shape() (h5s.Dataspace, error) { return p.mem.Copy() }
+
+// A buffer seen through another memory dataspace
+type ipiece struct {
+	IBuffer
+	mem h5s.Dataspace
+}
+
+// The memory dataspace, copied as it is closed after use
+func (p ipiece) Shape() (h5s.Dataspace, error) { return p.mem.Copy() }
+
+// Reads the data into the provided buffer like Read, but piece by
+// piece: the selection is split into hyperslabs spanning whole
+// chunks along the first axis, and the context is checked between
+// pieces, returning its error if it is done. The progress, if not
+// nil, is reported after each piece. The buffer must be contiguous,
+// i.e. have all of its elements selected.
+func (d Dataset) ReadContext(ctx context.Context, data OBuffer,
+	selection h5s.Dataspace, xfr Xfer, progress Progress) error {
+	return d.pieces(ctx, data, selection, progress,
+		func(file, mem h5s.Dataspace) error {
+			return d.Read(opiece{data, mem}, file, xfr)
+		})
+}
+
+// Writes the content of the buffer in the dataset like Write, but
+// piece by piece (see ReadContext)
+func (d Dataset) WriteContext(ctx context.Context, data IBuffer,
+	selection h5s.Dataspace, xfr Xfer, progress Progress) error {
+	return d.pieces(ctx, data, selection, progress,
+		func(file, mem h5s.Dataspace) error {
+			return d.Write(ipiece{data, mem}, file, xfr)
+		})
+}
+
+// Splits the selection into pieces spanning whole chunks along the
+// first axis, and calls `transfer` on each of them with the selection
+// of the piece in the dataset and the matching elements in memory.
+// As the elements are transferred in row-major order, each piece
+// maps onto a contiguous range of the buffer. Point selections,
+// whose elements are transferred in the order of the points, are
+// transferred as a single piece.
+func (d Dataset) pieces(ctx context.Context, data view,
+	selection h5s.Dataspace, progress Progress,
+	transfer func(file, mem h5s.Dataspace) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	space, err := d.Shape()
+	if err != nil {
+		return err
+	}
+	defer space.Close()
+	if selection == h5s.ALL {
+		selection = space
+	}
+	total, err := selection.GetSelNPoints()
+	if err != nil {
+		return err
+	}
+	if err := contiguous(data, total, space); err != nil {
+		return err
+	}
+	if progress == nil {
+		progress = func(int, int) {}
+	}
+	if total == 0 {
+		progress(0, 0)
+		return nil
+	}
+	dims, _, err := space.GetDims()
+	if err != nil {
+		return err
+	}
+	seltype, err := selection.GetSelType()
+	if err != nil {
+		return err
+	}
+	mem, err := h5s.CreateSimple([]int{total}, nil)
+	if err != nil {
+		return err
+	}
+	defer mem.Close()
+	if len(dims) == 0 || seltype == h5s.SEL_POINTS {
+		if err := transfer(selection, mem); err != nil {
+			return err
+		}
+		progress(total, total)
+		return nil
+	}
+	band, err := d.band(dims)
+	if err != nil {
+		return err
+	}
+	first, last, err := selection.GetSelBounds()
+	if err != nil {
+		return err
+	}
+	start := make([]uint, len(dims))
+	count := make([]uint, len(dims))
+	for i, n := range dims[1:] {
+		count[i+1] = uint(n)
+	}
+	done := 0
+	for row := int(first[0]) / band * band; row <= int(last[0]); row += band {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		start[0], count[0] = uint(row), uint(band)
+		if row+band > dims[0] {
+			count[0] = uint(dims[0] - row)
+		}
+		n, err := d.piece(selection, mem, start, count, done, transfer)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			done += n
+			progress(done, total)
+		}
+	}
+	return nil
+}
+
+// Transfers the elements of the selection within the hyperslab,
+// which are stored in memory from index `offset`, returning their
+// number
+func (d Dataset) piece(selection, mem h5s.Dataspace, start, count []uint,
+	offset int, transfer func(file, mem h5s.Dataspace) error) (int, error) {
+	file, err := selection.Copy()
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	if err := h5s.Hyperslab(file).Inter(start, nil, count, nil); err != nil {
+		return 0, err
+	}
+	n, err := file.GetSelNPoints()
+	if err != nil || n == 0 {
+		return 0, err
+	}
+	if err := h5s.Hyperslab(mem).Set([]uint{uint(offset)}, nil,
+		[]uint{uint(n)}, nil); err != nil {
+		return 0, err
+	}
+	return n, transfer(file, mem)
+}
+
+// The number of rows (along the first axis) transferred at once: a
+// multiple of the chunk size, spanning about PieceSize bytes
+func (d Dataset) band(dims []int) (int, error) {
+	unit := 1
+	crt, err := d.Creation()
+	if err != nil {
+		return 0, err
+	}
+	defer crt.Close()
+	if layout, err := crt.GetLayout(); err != nil {
+		return 0, err
+	} else if layout == Chunked {
+		chunk, err := crt.GetChunk()
+		if err != nil {
+			return 0, err
+		}
+		unit = chunk[0]
+	}
+	T, err := d.Type()
+	if err != nil {
+		return 0, err
+	}
+	defer T.Close()
+	size, err := T.GetSize()
+	if err != nil {
+		return 0, err
+	}
+	for _, n := range dims[1:] {
+		size *= n
+	}
+	if size*unit >= PieceSize || size == 0 {
+		return unit, nil
+	}
+	return unit * (PieceSize / (size * unit)), nil
+}
+
+// Checks that the memory dataspace of the buffer is contiguous and
+// holds the given number of elements. Buffers without a shape take
+// the shape of the dataset, so the entire dataset must be selected.
+func contiguous(data view, n int, space h5s.Dataspace) error {
+	mem, err := data.Shape()
+	if err != nil {
+		return err
+	}
+	if mem == h5s.ALL {
+		if size, err := space.GetNPoints(); err != nil {
+			return err
+		} else if size != n {
+			return fmt.Errorf("Expecting a buffer with a shape for a partial selection")
+		}
+		return nil
+	}
+	defer mem.Close()
+	seltype, err := mem.GetSelType()
+	if err != nil {
+		return err
+	}
+	size, err := mem.GetSelNPoints()
+	if err != nil {
+		return err
+	}
+	if seltype != h5s.SEL_ALL || size != n {
+		return fmt.Errorf("Expecting a contiguous buffer of %v elements", n)
+	}
+	return nil
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/valoox/h5go/h5p"
 	"github.com/valoox/h5go/h5s"
 	"github.com/valoox/h5go/h5t"
+	"github.com/valoox/h5go/h5z"
 )
 
 // Represents the layout of the data
@@ -31,6 +32,7 @@ const (
 	Compact    Layout = C.H5D_COMPACT
 	Contiguous Layout = C.H5D_CONTIGUOUS
 	Chunked    Layout = C.H5D_CHUNKED
+	Virtual    Layout = C.H5D_VIRTUAL
 )
 
 // Default property lists
@@ -72,6 +74,8 @@ func (self Crt) Copy() (Crt, error) {
 // and each dimension is the length of the chunk in that
 // dimension.
 func (self Crt) SetChunk(dims []int) error {
+	core.Lock()
+	defer core.Unlock()
 	ndims := len(dims)
 	cdims := make([]C.hsize_t, ndims)
 	for i, d := range dims {
@@ -84,6 +88,8 @@ func (self Crt) SetChunk(dims []int) error {
 
 // Gets the size of the chunks
 func (self Crt) GetChunk() ([]int, error) {
+	core.Lock()
+	defer core.Unlock()
 	n := 8
 	actual := n
 	out := make([]C.hsize_t, n)
@@ -106,19 +112,217 @@ func (self Crt) GetChunk() ([]int, error) {
 	return res, nil
 }
 
+// Sets the value used for the elements of the dataset which were
+// never written. The buffer provides the type and value of a single
+// element, which is converted to the type of the dataset
+// Wraps the H5Pset_fill_value function
+func (self Crt) SetFillValue(value IBuffer) error {
+	core.Lock()
+	defer core.Unlock()
+	T, err := value.Type()
+	if err != nil {
+		return err
+	}
+	defer T.Close()
+	return core.Status(int(C.H5Pset_fill_value(C.hid_t(self),
+		C.hid_t(T), value.ReadPtr())), "setting fill value")
+}
+
+// Gets the fill value into the buffer, which holds a single element
+// Wraps the H5Pget_fill_value function
+func (self Crt) GetFillValue(value OBuffer) error {
+	core.Lock()
+	defer core.Unlock()
+	T, err := value.Type()
+	if err != nil {
+		return err
+	}
+	defer T.Close()
+	return core.Status(int(C.H5Pget_fill_value(C.hid_t(self),
+		C.hid_t(T), value.WritePtr())), "getting fill value")
+}
+
 // Sets the layout of the dataset
 func (self Crt) SetLayout(layout Layout) error {
+	core.Lock()
+	defer core.Unlock()
 	return core.Status(int(C.H5Pset_layout(C.hid_t(self),
 		layout.C())), "setting dataset layout")
 }
 
 // Gets the layout of the dataset
 func (self Crt) GetLayout() (Layout, error) {
+	core.Lock()
+	defer core.Unlock()
 	layout := C.H5Pget_layout(C.hid_t(self))
 	return Layout(layout), core.Status(int(layout),
 		"getting layout")
 }
 
+// Adds the deflate (gzip) compression filter to the pipeline, with
+// the given compression level (0-9)
+// Wraps the H5Pset_deflate function
+func (self Crt) SetDeflate(level uint) error {
+	core.Lock()
+	defer core.Unlock()
+	if err := h5z.Require(h5z.DEFLATE, true); err != nil {
+		return err
+	}
+	return core.Status(int(C.H5Pset_deflate(C.hid_t(self),
+		C.unsigned(level))), "setting deflate filter")
+}
+
+// Adds the shuffle filter to the pipeline. This reorders the bytes
+// of the elements, which usually improves the compression ratio of
+// the following filters
+// Wraps the H5Pset_shuffle function
+func (self Crt) SetShuffle() error {
+	core.Lock()
+	defer core.Unlock()
+	if err := h5z.Require(h5z.SHUFFLE, true); err != nil {
+		return err
+	}
+	return core.Status(int(C.H5Pset_shuffle(C.hid_t(self))),
+		"setting shuffle filter")
+}
+
+// Adds the Fletcher32 checksum filter to the pipeline
+// Wraps the H5Pset_fletcher32 function
+func (self Crt) SetFletcher32() error {
+	core.Lock()
+	defer core.Unlock()
+	if err := h5z.Require(h5z.FLETCHER32, true); err != nil {
+		return err
+	}
+	return core.Status(int(C.H5Pset_fletcher32(C.hid_t(self))),
+		"setting fletcher32 filter")
+}
+
+// Adds the N-bit filter to the pipeline, which packs the significant
+// bits of the elements (as defined by their datatype)
+// Wraps the H5Pset_nbit function
+func (self Crt) SetNbit() error {
+	core.Lock()
+	defer core.Unlock()
+	if err := h5z.Require(h5z.NBIT, true); err != nil {
+		return err
+	}
+	return core.Status(int(C.H5Pset_nbit(C.hid_t(self))),
+		"setting nbit filter")
+}
+
+// Adds the scale-offset filter to the pipeline. The factor is the
+// number of decimal digits (FLOAT_DSCALE) or bits (INT) to keep
+// Wraps the H5Pset_scaleoffset function
+func (self Crt) SetScaleOffset(scale h5z.ScaleType, factor int) error {
+	core.Lock()
+	defer core.Unlock()
+	if err := h5z.Require(h5z.SCALEOFFSET, true); err != nil {
+		return err
+	}
+	return core.Status(int(C.H5Pset_scaleoffset(C.hid_t(self),
+		C.H5Z_SO_scale_type_t(scale), C.int(factor))),
+		"setting scaleoffset filter")
+}
+
+// Adds the szip compression filter to the pipeline. The options
+// mask combines h5z.SZIP_EC or h5z.SZIP_NN, and the number of pixels
+// per block must be even and at most 32
+// Wraps the H5Pset_szip function
+func (self Crt) SetSzip(mask uint, pixels uint) error {
+	core.Lock()
+	defer core.Unlock()
+	if err := h5z.Require(h5z.SZIP, true); err != nil {
+		return err
+	}
+	return core.Status(int(C.H5Pset_szip(C.hid_t(self),
+		C.unsigned(mask), C.unsigned(pixels))),
+		"setting szip filter")
+}
+
+// Adds an arbitrary filter to the pipeline, with its flags and
+// auxiliary parameters. Mandatory filters must be available, while
+// optional ones (see h5z.OPTIONAL) are skipped when they are not.
+// Wraps the H5Pset_filter function
+func (self Crt) SetFilter(id h5z.Filter, flags h5z.Flag, cdValues []uint) error {
+	core.Lock()
+	defer core.Unlock()
+	if flags&h5z.OPTIONAL == 0 {
+		if err := h5z.Require(id, true); err != nil {
+			return err
+		}
+	}
+	var cd *C.unsigned
+	if len(cdValues) > 0 {
+		cvals := make([]C.unsigned, len(cdValues))
+		for i, v := range cdValues {
+			cvals[i] = C.unsigned(v)
+		}
+		cd = &cvals[0]
+	}
+	return core.Status(int(C.H5Pset_filter(C.hid_t(self),
+		C.H5Z_filter_t(id), C.unsigned(flags),
+		C.size_t(len(cdValues)), cd)),
+		"setting %s filter", id)
+}
+
+// Removes the filter from the pipeline
+// Wraps the H5Premove_filter function
+func (self Crt) RemoveFilter(id h5z.Filter) error {
+	core.Lock()
+	defer core.Unlock()
+	return core.Status(int(C.H5Premove_filter(C.hid_t(self),
+		C.H5Z_filter_t(id))), "removing %s filter", id)
+}
+
+// Gets the description of the filters of the pipeline, in the order
+// in which they are applied when writing
+// Wraps the H5Pget_nfilters and H5Pget_filter2 functions
+func (self Crt) GetFilters() ([]h5z.Info, error) {
+	core.Lock()
+	defer core.Unlock()
+	n := C.H5Pget_nfilters(C.hid_t(self))
+	if err := core.Status(int(n),
+		"getting number of filters"); err != nil {
+		return nil, err
+	}
+	out := make([]h5z.Info, int(n))
+	for i := range out {
+		var flags, config C.unsigned
+		var id C.H5Z_filter_t
+		ncd := C.size_t(16)
+		cd := make([]C.unsigned, int(ncd))
+		name := make([]C.char, 256)
+		// Retries with the actual number of values if they did not fit
+		for {
+			id = C.H5Pget_filter2(C.hid_t(self), C.unsigned(i),
+				&flags, &ncd, &cd[0],
+				C.size_t(len(name)), &name[0], &config)
+			if err := core.Status(int(id),
+				"getting filter %v", i); err != nil {
+				return nil, err
+			}
+			if int(ncd) <= len(cd) {
+				break
+			}
+			cd = make([]C.unsigned, int(ncd))
+		}
+		vals := make([]uint, int(ncd))
+		for j := range vals {
+			vals[j] = uint(cd[j])
+		}
+		out[i] = h5z.Info{
+			Id:       h5z.Filter(id),
+			Flags:    h5z.Flag(flags),
+			CdValues: vals,
+			Name:     C.GoString(&name[0]),
+			Encode:   config&C.H5Z_FILTER_CONFIG_ENCODE_ENABLED != 0,
+			Decode:   config&C.H5Z_FILTER_CONFIG_DECODE_ENABLED != 0,
+		}
+	}
+	return out, nil
+}
+
 // Creates a new property list for accessing a dataset
 func Access() (Acc, error) {
 	id, err := h5p.Create(h5p.DATASET_ACCESS)
@@ -161,7 +365,10 @@ type Dataset core.Id
 // The dataspace for this dataset
 // Wraps the H5Dget_space function
 func (d Dataset) Shape() (h5s.Dataspace, error) {
+	core.Lock()
+	defer core.Unlock()
 	out := h5s.Dataspace(C.H5Dget_space(C.hid_t(d)))
+	core.Track(core.Id(out))
 	return out, core.Status(int(out),
 		"getting associated dataspace of dataset %v",
 		d)
@@ -170,27 +377,80 @@ func (d Dataset) Shape() (h5s.Dataspace, error) {
 // The datatype for this dataset
 // Wraps the H5Dget_type function
 func (d Dataset) Type() (h5t.Datatype, error) {
+	core.Lock()
+	defer core.Unlock()
 	out := h5t.Datatype(C.H5Dget_type(C.hid_t(d)))
+	core.Track(core.Id(out))
 	return out, core.Status(int(out),
 		"getting associated datatype of dataset %v",
 		d)
 }
 
+// A copy of the creation property list of the dataset, which
+// should be closed after use
+// Wraps the H5Dget_create_plist function
+func (d Dataset) Creation() (Crt, error) {
+	core.Lock()
+	defer core.Unlock()
+	out := Crt(C.H5Dget_create_plist(C.hid_t(d)))
+	core.Track(core.Id(out))
+	return out, core.Status(int(out),
+		"getting creation property list of dataset %v", d)
+}
+
+// A copy of the access property list of the dataset, which should
+// be closed after use
+// Wraps the H5Dget_access_plist function
+func (d Dataset) Access() (Acc, error) {
+	core.Lock()
+	defer core.Unlock()
+	out := Acc(C.H5Dget_access_plist(C.hid_t(d)))
+	core.Track(core.Id(out))
+	return out, core.Status(int(out),
+		"getting access property list of dataset %v", d)
+}
+
 // The HDF5 Id for this dataset
 func (d Dataset) Id() core.Id { return core.Id(d) }
 
 // Closes the dataset
 // Wraps the H5Dclose function
 func (d Dataset) Close() error {
+	core.Lock()
+	defer core.Unlock()
 	return core.Status(int(C.H5Dclose(C.hid_t(d))),
 		"closing dataset")
 }
 
+// Reloads the metadata of the dataset (such as its dimensions),
+// which can have been changed by the writer of a file opened for
+// single-writer/multiple-readers access
+// Wraps the H5Drefresh function
+func (d Dataset) Refresh() error {
+	core.Lock()
+	defer core.Unlock()
+	return core.Status(int(C.H5Drefresh(C.hid_t(d))),
+		"refreshing dataset %v", d)
+}
+
+// Flushes the data and metadata of the dataset to the disk, so that
+// readers of a file in single-writer/multiple-readers mode can see
+// them
+// Wraps the H5Dflush function
+func (d Dataset) Flush() error {
+	core.Lock()
+	defer core.Unlock()
+	return core.Status(int(C.H5Dflush(C.hid_t(d))),
+		"flushing dataset %v", d)
+}
+
 // Sets the dimensions of the dataset to the given dimensions
 // Returns an error if the dataset is not chunked, or if the
 // dimensions are superior to the maximum dimension available
 // Wraps the H5Dset_extent function
 func (d Dataset) SetDims(dims []int) error {
+	core.Lock()
+	defer core.Unlock()
 	cdims := make([]C.hsize_t, len(dims))
 	for i, x := range dims {
 		cdims[i] = C.hsize_t(x)
@@ -203,6 +463,8 @@ func (d Dataset) SetDims(dims []int) error {
 // Writes the content of the buffer in the dataset
 // Wraps the H5Dwrite function
 func (d Dataset) Write(data IBuffer, selection h5s.Dataspace, xfr Xfer) error {
+	core.Lock()
+	defer core.Unlock()
 	T, err := data.Type()
 	if err != nil {
 		return err
@@ -225,6 +487,8 @@ func (d Dataset) Write(data IBuffer, selection h5s.Dataspace, xfr Xfer) error {
 
 // Reads the data into the provided buffer
 func (d Dataset) Read(data OBuffer, selection h5s.Dataspace, xfr Xfer) error {
+	core.Lock()
+	defer core.Unlock()
 	T, err := data.Type()
 	if err != nil {
 		return err
@@ -247,6 +511,7 @@ func (d Dataset) Read(data OBuffer, selection h5s.Dataspace, xfr Xfer) error {
 
 // Tries to return the status, raising and error if it is negative
 func try(id Dataset, context string, args ...interface{}) (Dataset, error) {
+	core.Track(core.Id(id))
 	return id, core.Status(int(id), fmt.Sprintf(context, args...))
 }
 
@@ -255,6 +520,8 @@ func try(id Dataset, context string, args ...interface{}) (Dataset, error) {
 func Create(at core.Location, name core.Path, dtype h5t.Datatype,
 	dspace h5s.Dataspace,
 	link h5l.Crt, c Crt, a Acc) (Dataset, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(Dataset(C.H5Dcreate2(C.hid_t(at.At()),
 		C.CString(name.String()),
 		C.hid_t(dtype), C.hid_t(dspace),
@@ -267,6 +534,8 @@ func Create(at core.Location, name core.Path, dtype h5t.Datatype,
 // Opens an existing location, from a root location and a path
 // Wraps the H5Dopen function
 func Open(at core.Location, name core.Path, access Acc) (Dataset, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(Dataset(C.H5Dopen2(C.hid_t(at.At()),
 		C.CString(name.String()), C.hid_t(access))),
 		"opening dataset at %s", name)
@@ -0,0 +1,256 @@
+package h5d
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"unsafe"
+)
+
+import (
+	"github.com/valoox/h5go/h5s"
+	"github.com/valoox/h5go/h5t"
+)
+
+// Streams the bytes of a one-dimensional dataset of bytes (either
+// 8-bit integers, or opaque values such as h5t.RawBin), using
+// hyperslab selections so that only the bytes requested are read or
+// written. It implements io.ReaderAt, io.WriterAt, io.ReadSeeker and
+// io.Writer.
+// Writing past the end of the dataset extends it, which requires the
+// dataset to be chunked and its maximum size to allow it. For opaque
+// values of several bytes, the size of the stream is always a
+// multiple of the size of the values, the last one being padded with
+// zeros.
+// The extent of the dataset is read again on each operation, so that
+// other handles on the same dataset can extend it; when another
+// process writes the file, the dataset must rather be refreshed (see
+// Dataset.Refresh).
+type Stream struct {
+	ds    Dataset      // The dataset
+	dtype h5t.Datatype // The datatype of the dataset
+	esize int          // The size of each element, in bytes
+	n     int64        // The number of elements of the dataset
+	max   int64        // The maximum number of elements, or -1
+	pos   int64        // The current offset, in bytes
+}
+
+// Wraps the dataset into a stream, starting at offset 0. The stream
+// should be closed after use, which does not close the dataset.
+func NewStream(ds Dataset) (*Stream, error) {
+	out := &Stream{ds: ds}
+	if err := out.extent(); err != nil {
+		return nil, err
+	}
+	T, err := ds.Type()
+	if err != nil {
+		return nil, err
+	}
+	out.dtype = T
+	cls, err := T.GetClass()
+	if err == nil {
+		out.esize, err = T.GetSize()
+	}
+	if err != nil {
+		T.Close()
+		return nil, err
+	}
+	if cls != h5t.OPAQUE && (cls != h5t.INTEGER || out.esize != 1) {
+		T.Close()
+		return nil, fmt.Errorf("Expecting a dataset of bytes, got %s", cls.Name())
+	}
+	return out, nil
+}
+
+// Reads the current and maximum number of elements of the dataset
+func (s *Stream) extent() error {
+	space, err := s.ds.Shape()
+	if err != nil {
+		return err
+	}
+	defer space.Close()
+	dims, maxs, err := space.GetDims()
+	if err != nil {
+		return err
+	}
+	if len(dims) != 1 {
+		return fmt.Errorf("Expecting a one-dimensional dataset, got %v dimensions", len(dims))
+	}
+	s.n, s.max = int64(dims[0]), int64(maxs[0])
+	return nil
+}
+
+// The dataset streamed
+func (s *Stream) Dataset() Dataset { return s.ds }
+
+// The current size of the stream, in bytes. If the extent of the
+// dataset cannot be read, the last one known is used.
+func (s *Stream) Size() int64 {
+	s.extent()
+	return s.n * int64(s.esize)
+}
+
+// Releases the datatype of the stream. The dataset is left open
+func (s *Stream) Close() error { return s.dtype.Close() }
+
+// Reads len(p) bytes from offset `off` (see io.ReaderAt)
+func (s *Stream) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("Negative offset")
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if err := s.extent(); err != nil {
+		return 0, err
+	}
+	size := s.n * int64(s.esize)
+	if off >= size {
+		return 0, io.EOF
+	}
+	n := len(p)
+	if int64(n) > size-off {
+		n = int(size - off)
+	}
+	first, last := s.elements(off, n)
+	if err := s.transfer(first, last, p[:n], off, false); err != nil {
+		return 0, err
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Writes the bytes at offset `off`, extending the dataset as needed
+// (see io.WriterAt)
+func (s *Stream) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("Negative offset")
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if err := s.extent(); err != nil {
+		return 0, err
+	}
+	first, last := s.elements(off, len(p))
+	if last > s.n {
+		if s.max >= 0 && last > s.max {
+			return 0, fmt.Errorf("Cannot extend dataset beyond %v elements", s.max)
+		}
+		if err := s.ds.SetDims([]int{int(last)}); err != nil {
+			return 0, err
+		}
+		s.n = last
+	}
+	if err := s.transfer(first, last, p, off, true); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Reads up to len(p) bytes from the current offset (see io.Reader)
+func (s *Stream) Read(p []byte) (int, error) {
+	n, err := s.ReadAt(p, s.pos)
+	s.pos += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// Writes the bytes at the current offset (see io.Writer)
+func (s *Stream) Write(p []byte) (int, error) {
+	n, err := s.WriteAt(p, s.pos)
+	s.pos += int64(n)
+	return n, err
+}
+
+// Sets the offset of the next Read or Write (see io.Seeker)
+func (s *Stream) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.pos
+	case io.SeekEnd:
+		offset += s.Size()
+	default:
+		return 0, fmt.Errorf("Invalid whence: %v", whence)
+	}
+	if offset < 0 {
+		return 0, errors.New("Negative offset")
+	}
+	s.pos = offset
+	return offset, nil
+}
+
+// The range of elements [first, last) holding `n` bytes from `off`
+func (s *Stream) elements(off int64, n int) (first, last int64) {
+	esize := int64(s.esize)
+	return off / esize, (off + int64(n) + esize - 1) / esize
+}
+
+// Reads (or writes) the elements [first, last), which hold the bytes
+// of `p` from offset `off`. Partial elements are read beforehand when
+// writing, so that the bytes around `p` are kept
+func (s *Stream) transfer(first, last int64, p []byte, off int64, write bool) error {
+	buf := p
+	skip := off - first*int64(s.esize)
+	partial := skip != 0 || int64(len(p)) != (last-first)*int64(s.esize)
+	if partial {
+		buf = make([]byte, (last-first)*int64(s.esize))
+	}
+	space, err := s.ds.Shape()
+	if err != nil {
+		return err
+	}
+	defer space.Close()
+	if err := h5s.Hyperslab(space).Set([]uint{uint(first)}, nil,
+		[]uint{uint(last - first)}, nil); err != nil {
+		return err
+	}
+	mem := &octets{s.dtype, buf}
+	if !write || partial {
+		if err := s.ds.Read(mem, space, DefaultXfer); err != nil {
+			return err
+		}
+	}
+	if !write {
+		if partial {
+			copy(p, buf[skip:])
+		}
+		return nil
+	}
+	if partial {
+		copy(buf[skip:], p)
+	}
+	return s.ds.Write(mem, space, DefaultXfer)
+}
+
+// A contiguous buffer of bytes, holding elements of the datatype
+type octets struct {
+	dtype h5t.Datatype
+	data  []byte
+}
+
+// Implements the Buffer interface. The datatype is copied, as it is
+// closed after use
+func (b *octets) Type() (h5t.Datatype, error) { return b.dtype.Copy() }
+func (b *octets) Shape() (h5s.Dataspace, error) {
+	esize, err := b.dtype.GetSize()
+	if err != nil {
+		return -1, err
+	}
+	return h5s.CreateSimple([]int{len(b.data) / esize}, nil)
+}
+func (b *octets) ReadPtr() unsafe.Pointer  { return b.ptr() }
+func (b *octets) WritePtr() unsafe.Pointer { return b.ptr() }
+
+// The pointer to the bytes, or nil if there are none
+func (b *octets) ptr() unsafe.Pointer {
+	if len(b.data) == 0 {
+		return nil
+	}
+	return unsafe.Pointer(&b.data[0])
+}
@@ -0,0 +1,218 @@
+package h5d
+
+/*
+#include <stdlib.h>
+#include <hdf5.h>
+*/
+import "C"
+import "unsafe"
+
+import (
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5s"
+)
+
+// Maps the elements selected in `vspace`, the dataspace of a virtual
+// dataset, to the elements selected in `srcSpace`, the dataspace of
+// the source dataset at path `srcDataset` in file `srcFile` (which
+// can be "." for the file of the virtual dataset). Both selections
+// must have the same number of elements. This sets the layout to
+// Virtual, and can be called once per mapping.
+// The names can contain printf-like patterns, `%b` being replaced by
+// the index of the block of an unlimited selection (see
+// h5s.UNLIMITED), so that each block is read from a different file
+// or dataset: a virtual dataset with an unlimited dimension then
+// spans as many source files as exist (see Acc.SetVirtualView).
+// Wraps the H5Pset_virtual function
+func (self Crt) SetVirtual(vspace h5s.Dataspace, srcFile string,
+	srcDataset core.Path, srcSpace h5s.Dataspace) error {
+	core.Lock()
+	defer core.Unlock()
+	cfile := C.CString(srcFile)
+	defer C.free(unsafe.Pointer(cfile))
+	cdset := C.CString(string(srcDataset))
+	defer C.free(unsafe.Pointer(cdset))
+	return core.Status(int(C.H5Pset_virtual(C.hid_t(self),
+		C.hid_t(vspace), cfile, cdset, C.hid_t(srcSpace))),
+		"mapping %s:%s in virtual dataset", srcFile, srcDataset)
+}
+
+// Gets the number of mappings of a virtual dataset
+// Wraps the H5Pget_virtual_count function
+func (self Crt) GetVirtualCount() (int, error) {
+	core.Lock()
+	defer core.Unlock()
+	var n C.size_t
+	err := core.Status(int(C.H5Pget_virtual_count(C.hid_t(self), &n)),
+		"getting number of virtual mappings")
+	return int(n), err
+}
+
+// Gets a copy of the dataspace of the virtual dataset, where the
+// elements of the i-th mapping are selected. It should be closed
+// after use.
+// Wraps the H5Pget_virtual_vspace function
+func (self Crt) GetVirtualVSpace(i int) (h5s.Dataspace, error) {
+	core.Lock()
+	defer core.Unlock()
+	id := h5s.Dataspace(C.H5Pget_virtual_vspace(C.hid_t(self),
+		C.size_t(i)))
+	core.Track(core.Id(id))
+	return id, core.Status(int(id), "getting virtual dataspace %v", i)
+}
+
+// Gets a copy of the dataspace of the source dataset, where the
+// elements of the i-th mapping are selected. It should be closed
+// after use.
+// Wraps the H5Pget_virtual_srcspace function
+func (self Crt) GetVirtualSrcSpace(i int) (h5s.Dataspace, error) {
+	core.Lock()
+	defer core.Unlock()
+	id := h5s.Dataspace(C.H5Pget_virtual_srcspace(C.hid_t(self),
+		C.size_t(i)))
+	core.Track(core.Id(id))
+	return id, core.Status(int(id), "getting source dataspace %v", i)
+}
+
+// Gets the name of the source file of the i-th mapping, as provided
+// to SetVirtual (with its patterns, if any)
+// Wraps the H5Pget_virtual_filename function
+func (self Crt) GetVirtualFilename(i int) (string, error) {
+	core.Lock()
+	defer core.Unlock()
+	n := int(C.H5Pget_virtual_filename(C.hid_t(self), C.size_t(i), nil, 0))
+	if err := core.Status(n, "getting source file of mapping %v", i); err != nil {
+		return "", err
+	}
+	buf := make([]C.char, n+1)
+	err := core.Status(int(C.H5Pget_virtual_filename(C.hid_t(self),
+		C.size_t(i), &buf[0], C.size_t(len(buf)))),
+		"getting source file of mapping %v", i)
+	return C.GoString(&buf[0]), err
+}
+
+// Gets the path of the source dataset of the i-th mapping, as
+// provided to SetVirtual (with its patterns, if any)
+// Wraps the H5Pget_virtual_dsetname function
+func (self Crt) GetVirtualDsetname(i int) (core.Path, error) {
+	core.Lock()
+	defer core.Unlock()
+	n := int(C.H5Pget_virtual_dsetname(C.hid_t(self), C.size_t(i), nil, 0))
+	if err := core.Status(n, "getting source dataset of mapping %v", i); err != nil {
+		return "", err
+	}
+	buf := make([]C.char, n+1)
+	err := core.Status(int(C.H5Pget_virtual_dsetname(C.hid_t(self),
+		C.size_t(i), &buf[0], C.size_t(len(buf)))),
+		"getting source dataset of mapping %v", i)
+	return core.Path(C.GoString(&buf[0])), err
+}
+
+// Describes a mapping of a virtual dataset. The dataspaces are
+// copies, which should be closed after use
+type Mapping struct {
+	VSpace   h5s.Dataspace // The elements of the virtual dataset
+	File     string        // The source file
+	Dataset  core.Path     // The source dataset
+	SrcSpace h5s.Dataspace // The elements of the source dataset
+}
+
+// Releases the dataspaces of the mapping
+func (m Mapping) Close() error {
+	err := m.VSpace.Close()
+	if serr := m.SrcSpace.Close(); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// Gets all the mappings of a virtual dataset, which should be closed
+// after use
+func (self Crt) GetVirtual() ([]Mapping, error) {
+	n, err := self.GetVirtualCount()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Mapping, 0, n)
+	release := func() {
+		for _, m := range out {
+			m.Close()
+		}
+	}
+	for i := 0; i < n; i++ {
+		var m Mapping
+		if m.File, err = self.GetVirtualFilename(i); err != nil {
+			release()
+			return nil, err
+		}
+		if m.Dataset, err = self.GetVirtualDsetname(i); err != nil {
+			release()
+			return nil, err
+		}
+		if m.VSpace, err = self.GetVirtualVSpace(i); err != nil {
+			release()
+			return nil, err
+		}
+		if m.SrcSpace, err = self.GetVirtualSrcSpace(i); err != nil {
+			m.VSpace.Close()
+			release()
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// Defines the extent of a virtual dataset with unlimited mappings
+type View int
+
+const (
+	// Up to the last element available in any source dataset
+	LastAvailable View = C.H5D_VDS_LAST_AVAILABLE
+	// Up to the first missing source dataset
+	FirstMissing View = C.H5D_VDS_FIRST_MISSING
+)
+
+// Sets the view of a virtual dataset, i.e. how its extent is
+// computed from the sources of its unlimited mappings
+// Wraps the H5Pset_virtual_view function
+func (self Acc) SetVirtualView(view View) error {
+	core.Lock()
+	defer core.Unlock()
+	return core.Status(int(C.H5Pset_virtual_view(C.hid_t(self),
+		C.H5D_vds_view_t(view))), "setting virtual view")
+}
+
+// Gets the view of a virtual dataset (see SetVirtualView)
+// Wraps the H5Pget_virtual_view function
+func (self Acc) GetVirtualView() (View, error) {
+	core.Lock()
+	defer core.Unlock()
+	var view C.H5D_vds_view_t
+	err := core.Status(int(C.H5Pget_virtual_view(C.hid_t(self), &view)),
+		"getting virtual view")
+	return View(view), err
+}
+
+// Sets the number of missing source files (or datasets) tolerated
+// when looking for the sources of a printf-like mapping: the files
+// after a gap of more than `gap` missing files are ignored
+// Wraps the H5Pset_virtual_printf_gap function
+func (self Acc) SetVirtualPrintfGap(gap int) error {
+	core.Lock()
+	defer core.Unlock()
+	return core.Status(int(C.H5Pset_virtual_printf_gap(C.hid_t(self),
+		C.hsize_t(gap))), "setting virtual printf gap")
+}
+
+// Gets the number of missing source files tolerated (see
+// SetVirtualPrintfGap)
+// Wraps the H5Pget_virtual_printf_gap function
+func (self Acc) GetVirtualPrintfGap() (int, error) {
+	core.Lock()
+	defer core.Unlock()
+	var gap C.hsize_t
+	err := core.Status(int(C.H5Pget_virtual_printf_gap(C.hid_t(self),
+		&gap)), "getting virtual printf gap")
+	return int(gap), err
+}
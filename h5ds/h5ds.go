@@ -0,0 +1,139 @@
+// This wraps the H5DS* functions of the high-level library, which
+// implement dimension scales: datasets holding the coordinates along
+// a dimension of other datasets. Scales are recorded following the
+// conventions of the library (the CLASS=DIMENSION_SCALE, NAME,
+// REFERENCE_LIST and DIMENSION_LIST attributes), which are those
+// expected by netCDF-4 and the tools built on it.
+package h5ds
+
+/*
+#cgo LDFLAGS: -lhdf5_hl -lhdf5
+#include <stdlib.h>
+#include <hdf5.h>
+#include <hdf5_hl.h>
+*/
+import "C"
+import "unsafe"
+
+import (
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5d"
+)
+
+// Converts the dataset into a dimension scale, with the given name
+// (which can be empty)
+// Wraps the H5DSset_scale function
+func SetScale(ds h5d.Dataset, name string) error {
+	core.Lock()
+	defer core.Unlock()
+	var cname *C.char
+	if name != "" {
+		cname = C.CString(name)
+		defer C.free(unsafe.Pointer(cname))
+	}
+	return core.Status(int(C.H5DSset_scale(C.hid_t(ds), cname)),
+		"setting dimension scale %s", name)
+}
+
+// Checks whether the dataset is a dimension scale
+// Wraps the H5DSis_scale function
+func IsScale(ds h5d.Dataset) (bool, error) {
+	core.Lock()
+	defer core.Unlock()
+	res := C.H5DSis_scale(C.hid_t(ds))
+	return res > 0, core.Status(int(res), "checking dimension scale")
+}
+
+// Gets the name of the dimension scale
+// Wraps the H5DSget_scale_name function
+func GetScaleName(scale h5d.Dataset) (string, error) {
+	core.Lock()
+	defer core.Unlock()
+	sze := C.H5DSget_scale_name(C.hid_t(scale), nil, 0)
+	if err := core.Status(int(sze),
+		"getting dimension scale name"); err != nil || sze == 0 {
+		return "", err
+	}
+	out := make([]byte, int(sze)+1)
+	if err := core.Status(int(C.H5DSget_scale_name(C.hid_t(scale),
+		(*C.char)(unsafe.Pointer(&out[0])), C.size_t(sze+1))),
+		"getting dimension scale name"); err != nil {
+		return "", err
+	}
+	return string(out[:sze]), nil
+}
+
+// Attaches the dimension scale to the dimension `dim` of the dataset
+// Wraps the H5DSattach_scale function
+func Attach(ds, scale h5d.Dataset, dim int) error {
+	core.Lock()
+	defer core.Unlock()
+	return core.Status(int(C.H5DSattach_scale(C.hid_t(ds),
+		C.hid_t(scale), C.unsigned(dim))),
+		"attaching dimension scale to dimension %v", dim)
+}
+
+// Detaches the dimension scale from the dimension `dim` of the dataset
+// Wraps the H5DSdetach_scale function
+func Detach(ds, scale h5d.Dataset, dim int) error {
+	core.Lock()
+	defer core.Unlock()
+	return core.Status(int(C.H5DSdetach_scale(C.hid_t(ds),
+		C.hid_t(scale), C.unsigned(dim))),
+		"detaching dimension scale from dimension %v", dim)
+}
+
+// Checks whether the dimension scale is attached to the dimension
+// `dim` of the dataset
+// Wraps the H5DSis_attached function
+func IsAttached(ds, scale h5d.Dataset, dim int) (bool, error) {
+	core.Lock()
+	defer core.Unlock()
+	res := C.H5DSis_attached(C.hid_t(ds), C.hid_t(scale),
+		C.unsigned(dim))
+	return res > 0, core.Status(int(res),
+		"checking dimension scale of dimension %v", dim)
+}
+
+// Gets the number of scales attached to the dimension `dim` of the
+// dataset
+// Wraps the H5DSget_num_scales function
+func NumScales(ds h5d.Dataset, dim int) (int, error) {
+	core.Lock()
+	defer core.Unlock()
+	n := int(C.H5DSget_num_scales(C.hid_t(ds), C.unsigned(dim)))
+	return n, core.Status(n, "counting scales of dimension %v", dim)
+}
+
+// Sets the label of the dimension `dim` of the dataset
+// Wraps the H5DSset_label function
+func SetLabel(ds h5d.Dataset, dim int, label string) error {
+	core.Lock()
+	defer core.Unlock()
+	clabel := C.CString(label)
+	defer C.free(unsafe.Pointer(clabel))
+	return core.Status(int(C.H5DSset_label(C.hid_t(ds),
+		C.unsigned(dim), clabel)),
+		"setting label of dimension %v", dim)
+}
+
+// Gets the label of the dimension `dim` of the dataset, which is
+// empty if none was set
+// Wraps the H5DSget_label function
+func GetLabel(ds h5d.Dataset, dim int) (string, error) {
+	core.Lock()
+	defer core.Unlock()
+	sze := C.H5DSget_label(C.hid_t(ds), C.unsigned(dim), nil, 0)
+	if err := core.Status(int(sze),
+		"getting label of dimension %v", dim); err != nil || sze == 0 {
+		return "", err
+	}
+	out := make([]byte, int(sze)+1)
+	if err := core.Status(int(C.H5DSget_label(C.hid_t(ds),
+		C.unsigned(dim), (*C.char)(unsafe.Pointer(&out[0])),
+		C.size_t(sze+1))),
+		"getting label of dimension %v", dim); err != nil {
+		return "", err
+	}
+	return string(out[:sze]), nil
+}
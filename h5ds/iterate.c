@@ -0,0 +1,14 @@
+#include <stdint.h>
+#include <hdf5.h>
+#include <hdf5_hl.h>
+#include "_cgo_export.h"
+
+// Forwards the scales to the Go callback
+static herr_t scale_cb(hid_t ds, unsigned dim, hid_t scale, void *data) {
+  return goScaleCallback(scale, (uintptr_t)data);
+}
+
+// Iterates over the scales attached to the dimension of the dataset
+herr_t h5ds_iterate(hid_t ds, unsigned dim, uintptr_t h) {
+  return H5DSiterate_scales(ds, dim, NULL, scale_cb, (void *)h);
+}
@@ -0,0 +1,60 @@
+package h5ds
+
+/*
+#include <stdint.h>
+#include <hdf5.h>
+
+herr_t h5ds_iterate(hid_t, unsigned, uintptr_t);
+*/
+import "C"
+import "runtime/cgo"
+
+import (
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5d"
+)
+
+// The function called for each scale attached to a dimension. The
+// scale is only open during the call, and must not be closed (nor
+// used afterwards). Returning core.Stop ends the iteration early,
+// while any other error aborts it and is returned by the iteration.
+type IterFunc func(scale h5d.Dataset) error
+
+// The state of an iteration, shared with the C callback
+type iteration struct {
+	fn  IterFunc // The Go callback
+	err error    // The error returned by the callback, if any
+}
+
+// Called by the library for each scale
+//
+//export goScaleCallback
+func goScaleCallback(scale C.hid_t, h C.uintptr_t) C.herr_t {
+	it := cgo.Handle(h).Value().(*iteration)
+	switch err := it.fn(h5d.Dataset(scale)); err {
+	case nil:
+		return 0
+	case core.Stop:
+		return 1
+	default:
+		it.err = err
+		return -1
+	}
+}
+
+// Calls the function on each of the scales attached to the dimension
+// `dim` of the dataset
+// Wraps the H5DSiterate_scales function
+func Iterate(ds h5d.Dataset, dim int, fn IterFunc) error {
+	core.Lock()
+	defer core.Unlock()
+	it := &iteration{fn: fn}
+	h := cgo.NewHandle(it)
+	defer h.Delete()
+	res := C.h5ds_iterate(C.hid_t(ds), C.unsigned(dim), C.uintptr_t(h))
+	if it.err != nil {
+		return it.err
+	}
+	return core.Status(int(res),
+		"iterating over scales of dimension %v", dim)
+}
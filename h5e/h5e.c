@@ -0,0 +1,71 @@
+#include <stdint.h>
+#include <hdf5.h>
+#include "_cgo_export.h"
+
+// Forwards the frames of the error stack to the Go callback
+static herr_t walk_cb(unsigned n, const H5E_error2_t *err, void *data) {
+  return goWalkCallback((H5E_error2_t *)err, (uintptr_t)data);
+}
+
+// Walks the default error stack, from the innermost frame outwards
+herr_t h5e_walk(uintptr_t h) {
+  return H5Ewalk2(H5E_DEFAULT, H5E_WALK_UPWARD, walk_cb, (void *)h);
+}
+
+// Forwards the automatic error reporting to the Go logger
+static herr_t auto_cb(hid_t estack, void *data) {
+  goAutoCallback();
+  return 0;
+}
+
+// Sets the automatic error reporting: 0 disables it, 1 restores the
+// default printing on stderr and 2 routes it to the Go logger
+herr_t h5e_set_auto(int mode) {
+  switch (mode) {
+  case 0:
+    return H5Eset_auto2(H5E_DEFAULT, NULL, NULL);
+  case 1:
+    return H5Eset_auto2(H5E_DEFAULT, (H5E_auto2_t)H5Eprint2, stderr);
+  default:
+    return H5Eset_auto2(H5E_DEFAULT, auto_cb, NULL);
+  }
+}
+
+// The reporting last applied to the calling thread (see h5e_apply)
+static __thread long long applied = 0;
+
+// Applies the requested reporting (as `gen<<2 | mode`) to the calling
+// thread, unless it already did: thread-safe builds keep it per thread
+herr_t h5e_apply(long long r) {
+  if (r == applied)
+    return 0;
+  if (h5e_set_auto((int)(r & 3)) < 0)
+    return -1;
+  applied = r;
+  return 0;
+}
+
+// The error codes, which are only available at runtime
+hid_t h5e_code(int which) {
+  switch (which) {
+  case 0:
+    return H5E_DATATYPE;
+  case 1:
+    return H5E_NOTFOUND;
+  case 2:
+    return H5E_EXISTS;
+  case 3:
+    return H5E_FILEEXISTS;
+  case 4:
+    return H5E_WRITEERROR;
+  case 5:
+    return H5E_CANTCONVERT;
+  case 6:
+    return H5E_ARGS;
+  case 7:
+    return H5E_FILE;
+  case 8:
+    return H5E_CACHE;
+  }
+  return -1;
+}
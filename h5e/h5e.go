@@ -0,0 +1,246 @@
+// This wraps the H5E* family of functions, which manage the error
+// stack of the library. Whenever a call fails, the library records
+// the chain of functions which failed along with their reasons:
+// core.Status captures this stack into the errors it returns, so
+// that they can be inspected with errors.Is and errors.As.
+package h5e
+
+/*
+#cgo LDFLAGS: -lhdf5
+#include <stdint.h>
+#include <hdf5.h>
+
+herr_t h5e_walk(uintptr_t);
+herr_t h5e_apply(long long);
+hid_t h5e_code(int);
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"runtime/cgo"
+	"sync"
+	"sync/atomic"
+)
+import "github.com/valoox/h5go/internal/lock"
+
+// Represents an HDF5 error code (either a major or a minor code)
+type Code int64
+
+// The message associated with the code
+// Wraps the H5Eget_msg function
+func (c Code) String() string {
+	lock.Lock()
+	defer lock.Unlock()
+	n := C.H5Eget_msg(C.hid_t(c), nil, nil, 0)
+	if n <= 0 {
+		return ""
+	}
+	out := make([]C.char, int(n)+1)
+	C.H5Eget_msg(C.hid_t(c), nil, &out[0], C.size_t(len(out)))
+	return C.GoString(&out[0])
+}
+
+// The codes used to classify the errors
+var (
+	majDatatype   = Code(C.h5e_code(0))
+	minNotFound   = Code(C.h5e_code(1))
+	minExists     = Code(C.h5e_code(2))
+	minFileExists = Code(C.h5e_code(3))
+	minWrite      = Code(C.h5e_code(4))
+	minConvert    = Code(C.h5e_code(5))
+	majArgs       = Code(C.h5e_code(6))
+	majFile       = Code(C.h5e_code(7))
+	majCache      = Code(C.h5e_code(8))
+)
+
+// The classes of errors which can be tested with errors.Is
+var (
+	// The object (link, attribute, file...) does not exist
+	ErrNotFound = errors.New("not found")
+	// The object (link, attribute, file...) already exists
+	ErrExists = errors.New("already exists")
+	// The file was opened read-only
+	ErrReadOnly = errors.New("read-only")
+	// The data cannot be converted between the datatypes
+	ErrConversion = errors.New("type conversion")
+)
+
+// A single frame of the error stack
+type Frame struct {
+	// The major and minor codes of the error
+	Major, Minor Code
+	// The function in which the error occured
+	Func string
+	// The source file and line at which the error occured
+	File string
+	Line int
+	// The description of the error
+	Desc string
+}
+
+// Textual representation of the frame
+func (f Frame) String() string {
+	return fmt.Sprintf("%s(): %s [%s: %s]", f.Func, f.Desc,
+		f.Major, f.Minor)
+}
+
+// Whether the frame belongs to the given class of errors
+func (f Frame) is(class error) bool {
+	switch class {
+	case ErrNotFound:
+		return f.Minor == minNotFound && f.Major != majDatatype
+	case ErrExists:
+		return f.Minor == minExists || f.Minor == minFileExists
+	case ErrReadOnly:
+		// The library checks the write intent from several places,
+		// but as a write error of the arguments, file or cache
+		return f.Minor == minWrite && (f.Major == majArgs ||
+			f.Major == majFile || f.Major == majCache)
+	case ErrConversion:
+		return f.Minor == minConvert ||
+			(f.Major == majDatatype && f.Minor == minNotFound)
+	}
+	return false
+}
+
+// The error stack of the library, from the innermost frame (where
+// the error originated) to the API function which failed
+type Stack []Frame
+
+// Reports the API function which failed, and the original cause
+func (s Stack) Error() string {
+	if len(s) == 0 {
+		return "empty error stack"
+	}
+	top, cause := s[len(s)-1], s[0]
+	if len(s) == 1 {
+		return top.String()
+	}
+	return fmt.Sprintf("%s(): %s: %s", top.Func, top.Desc, cause)
+}
+
+// Checks whether the stack belongs to one of the classes of errors
+// (ErrNotFound, ErrExists, ErrReadOnly or ErrConversion)
+func (s Stack) Is(target error) bool {
+	for _, f := range s {
+		if f.is(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Called by the library for each frame of the stack
+//
+//export goWalkCallback
+func goWalkCallback(err *C.H5E_error2_t, h C.uintptr_t) C.herr_t {
+	s := cgo.Handle(h).Value().(*Stack)
+	*s = append(*s, Frame{
+		Major: Code(err.maj_num),
+		Minor: Code(err.min_num),
+		Func:  C.GoString(err.func_name),
+		File:  C.GoString(err.file_name),
+		Line:  int(err.line),
+		Desc:  C.GoString(err.desc),
+	})
+	return 0
+}
+
+// Captures the current error stack of the library, returning nil if
+// it is empty. The stack is not cleared.
+// Wraps the H5Ewalk2 function
+func Current() Stack {
+	lock.Lock()
+	defer lock.Unlock()
+	var s Stack
+	h := cgo.NewHandle(&s)
+	defer h.Delete()
+	if C.h5e_walk(C.uintptr_t(h)) < 0 || len(s) == 0 {
+		return nil
+	}
+	return s
+}
+
+// The logger receiving the errors, if any
+var logger struct {
+	sync.Mutex
+	*log.Logger
+}
+
+// Called by the library whenever an API function fails
+//
+//export goAutoCallback
+func goAutoCallback() {
+	logger.Lock()
+	defer logger.Unlock()
+	if logger.Logger == nil {
+		return
+	}
+	if s := Current(); s != nil {
+		logger.Print(s.Error())
+	}
+}
+
+// The automatic error reporting requested, as `gen<<2 | mode`, where
+// the generation counts the requests. Thread-safe builds keep this
+// setting per thread, so each thread applies it when entering the
+// library if the generation changed (see apply).
+var reporting int64
+
+func init() { lock.OnEnter(apply) }
+
+// Applies the requested error reporting to the calling thread, which
+// holds the lock
+func apply() {
+	if r := atomic.LoadInt64(&reporting); r != 0 {
+		C.h5e_apply(C.longlong(r))
+	}
+}
+
+// Sets the automatic error reporting
+func setauto(mode int) error {
+	var r int64
+	for {
+		old := atomic.LoadInt64(&reporting)
+		r = (old>>2+1)<<2 | int64(mode)
+		if atomic.CompareAndSwapInt64(&reporting, old, r) {
+			break
+		}
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if C.h5e_apply(C.longlong(r)) < 0 {
+		return fmt.Errorf("Error while setting automatic error reporting")
+	}
+	return nil
+}
+
+// Sets whether the library automatically prints its error stack on
+// stderr whenever a function fails (which is the default). This
+// applies to all the threads calling the library.
+// Wraps the H5Eset_auto2 function
+func SetAuto(on bool) error {
+	logger.Lock()
+	logger.Logger = nil
+	logger.Unlock()
+	if on {
+		return setauto(1)
+	}
+	return setauto(0)
+}
+
+// Routes the automatic error reporting to the Go logger instead of
+// stderr. Passing nil disables the reporting altogether.
+// Wraps the H5Eset_auto2 function
+func SetLogger(l *log.Logger) error {
+	if l == nil {
+		return SetAuto(false)
+	}
+	logger.Lock()
+	logger.Logger = l
+	logger.Unlock()
+	return setauto(2)
+}
@@ -0,0 +1,149 @@
+package h5e_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"unsafe"
+)
+import (
+	"github.com/valoox/h5go/h5a"
+	"github.com/valoox/h5go/h5e"
+	"github.com/valoox/h5go/h5f"
+	"github.com/valoox/h5go/h5g"
+	"github.com/valoox/h5go/h5l"
+	"github.com/valoox/h5go/h5s"
+	"github.com/valoox/h5go/h5t"
+)
+
+// Checks that the error stack is captured on failure
+func TestStack(t *testing.T) {
+	const testfile = "./errors.h5"
+	if err := h5e.SetAuto(false); err != nil {
+		t.Fatal(err)
+	}
+	defer h5e.SetAuto(true)
+	fid, err := h5f.Create(testfile, h5f.TRUNC,
+		h5f.DefaultCreate, h5f.DefaultAccess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(testfile)
+	defer fid.Close()
+	_, err = h5g.Open(fid, "missing", h5g.DefaultAccess)
+	if err == nil {
+		t.Fatal("Expected an error opening a missing group")
+	}
+	if !errors.Is(err, h5e.ErrNotFound) {
+		t.Fatalf("Expected a not found error, got %s", err)
+	}
+	var stack h5e.Stack
+	if !errors.As(err, &stack) || len(stack) == 0 {
+		t.Fatalf("No error stack in %s", err)
+	}
+	t.Logf("Captured: %s", err)
+	gid, err := h5g.Create(fid, "grp", h5l.DefaultCreate,
+		h5g.DefaultCreate, h5g.DefaultAccess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gid.Close()
+	if _, err := h5g.Create(fid, "grp", h5l.DefaultCreate,
+		h5g.DefaultCreate, h5g.DefaultAccess); !errors.Is(err, h5e.ErrExists) {
+		t.Fatalf("Expected an already exists error, got %v", err)
+	}
+}
+
+// Checks that writing to a file opened read-only is classified
+func TestReadOnly(t *testing.T) {
+	const testfile = "./readonly.h5"
+	if err := h5e.SetAuto(false); err != nil {
+		t.Fatal(err)
+	}
+	defer h5e.SetAuto(true)
+	fid, err := h5f.Create(testfile, h5f.TRUNC,
+		h5f.DefaultCreate, h5f.DefaultAccess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(testfile)
+	if err := fid.Close(); err != nil {
+		t.Fatal(err)
+	}
+	fid, err = h5f.Open(testfile, h5f.RO, h5f.DefaultAccess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fid.Close()
+	if _, err := h5g.Create(fid, "grp", h5l.DefaultCreate,
+		h5g.DefaultCreate, h5g.DefaultAccess); !errors.Is(err, h5e.ErrReadOnly) {
+		t.Fatalf("Expected a read-only error creating a group, got %v", err)
+	}
+	T, err := h5t.Int32()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer T.Close()
+	S, err := h5s.CreateScalar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer S.Close()
+	if _, err := h5a.Create(fid, "attr", T, S,
+		h5a.DefaultCreate); !errors.Is(err, h5e.ErrReadOnly) {
+		t.Fatalf("Expected a read-only error creating an attribute, got %v", err)
+	}
+}
+
+// A buffer holding raw bytes of the given type
+type raw struct {
+	dtype h5t.Datatype
+	data  []byte
+}
+
+func (r raw) Type() (h5t.Datatype, error)   { return r.dtype.Copy() }
+func (r raw) Shape() (h5s.Dataspace, error) { return h5s.ALL, nil }
+func (r raw) ReadPtr() unsafe.Pointer       { return unsafe.Pointer(&r.data[0]) }
+
+// Checks that the failed conversions are classified
+func TestConversion(t *testing.T) {
+	const testfile = "./conversion.h5"
+	if err := h5e.SetAuto(false); err != nil {
+		t.Fatal(err)
+	}
+	defer h5e.SetAuto(true)
+	fid, err := h5f.Create(testfile, h5f.TRUNC,
+		h5f.DefaultCreate, h5f.DefaultAccess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(testfile)
+	defer fid.Close()
+	T, err := h5t.Int32()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer T.Close()
+	S, err := h5s.CreateScalar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer S.Close()
+	attr, err := h5a.Create(fid, "attr", T, S, h5a.DefaultCreate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer attr.Close()
+	str, err := h5t.String(8, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer str.Close()
+	err = attr.Write(raw{str, []byte("eight\x00\x00\x00")})
+	if !errors.Is(err, h5e.ErrConversion) {
+		t.Fatalf("Expected a conversion error, got %v", err)
+	}
+	if errors.Is(err, h5e.ErrReadOnly) {
+		t.Fatalf("Misclassified conversion error: %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+package h5f
+
+/*
+#include <stdlib.h>
+#include <hdf5.h>
+
+// The driver ids are macros calling the initialisation of the
+// drivers, which cgo cannot expand
+static hid_t h5f_driver(int i) {
+	switch (i) {
+	case 0: return H5FD_SEC2;
+	case 1: return H5FD_STDIO;
+	case 2: return H5FD_CORE;
+	case 3: return H5FD_FAMILY;
+	case 4: return H5FD_MULTI;
+	case 5: return H5FD_LOG;
+	}
+	return -1;
+}
+*/
+import "C"
+import (
+	"strings"
+	"unsafe"
+)
+import (
+	"github.com/valoox/h5go/core"
+)
+
+// The virtual file drivers, through which the library accesses files
+type Driver int
+
+const (
+	SEC2   Driver = iota // POSIX unbuffered I/O (the default)
+	STDIO                // Buffered I/O of the C standard library
+	CORE                 // Files held in memory
+	FAMILY               // Files split into members of a fixed size
+	MULTI                // Files split by type of data (incl. split)
+	LOG                  // SEC2, logging the I/O operations
+	OTHER                // Any other driver
+)
+
+// The name of the driver
+func (d Driver) String() string {
+	switch d {
+	case SEC2:
+		return "sec2"
+	case STDIO:
+		return "stdio"
+	case CORE:
+		return "core"
+	case FAMILY:
+		return "family"
+	case MULTI:
+		return "multi"
+	case LOG:
+		return "log"
+	}
+	return "other"
+}
+
+// Gets the driver of the file access properties
+// Wraps the H5Pget_driver function
+func (self Acc) GetDriver() (Driver, error) {
+	core.Lock()
+	defer core.Unlock()
+	id := C.H5Pget_driver(C.hid_t(self))
+	if err := core.Status(int(id), "getting driver"); err != nil {
+		return OTHER, err
+	}
+	for d := SEC2; d < OTHER; d++ {
+		if C.h5f_driver(C.int(d)) == id {
+			return d, nil
+		}
+	}
+	return OTHER, nil
+}
+
+// Uses the sec2 driver, performing unbuffered POSIX I/O. This is the
+// default driver.
+// Wraps the H5Pset_fapl_sec2 function
+func (self Acc) SetSec2() error {
+	core.Lock()
+	defer core.Unlock()
+	return core.Status(int(C.H5Pset_fapl_sec2(C.hid_t(self))),
+		"setting sec2 driver")
+}
+
+// Uses the stdio driver, performing buffered I/O through the C
+// standard library
+// Wraps the H5Pset_fapl_stdio function
+func (self Acc) SetStdio() error {
+	core.Lock()
+	defer core.Unlock()
+	return core.Status(int(C.H5Pset_fapl_stdio(C.hid_t(self))),
+		"setting stdio driver")
+}
+
+// Uses the core driver, which keeps the entire file in memory. The
+// memory grows by `increment` bytes at a time. If `backing` is set,
+// the file is written to the disk when it is closed; otherwise it
+// only lives in memory (see File.Image to get its content).
+// Wraps the H5Pset_fapl_core function
+func (self Acc) SetCore(increment int, backing bool) error {
+	core.Lock()
+	defer core.Unlock()
+	return core.Status(int(C.H5Pset_fapl_core(C.hid_t(self),
+		C.size_t(increment), C.hbool_t(backing))),
+		"setting core driver")
+}
+
+// Gets the parameters of the core driver (see SetCore)
+// Wraps the H5Pget_fapl_core function
+func (self Acc) GetCore() (increment int, backing bool, err error) {
+	core.Lock()
+	defer core.Unlock()
+	var inc C.size_t
+	var bs C.hbool_t
+	err = core.Status(int(C.H5Pget_fapl_core(C.hid_t(self), &inc, &bs)),
+		"getting core driver parameters")
+	return int(inc), bool(bs), err
+}
+
+// Uses the family driver, which splits the file into members of
+// `size` bytes. The path of the file must then contain a printf-like
+// integer pattern (e.g. "data-%d.h5"), replaced by the index of each
+// member. The members are accessed using the `member` properties,
+// which can be DefaultAccess.
+// Wraps the H5Pset_fapl_family function
+func (self Acc) SetFamily(size int, member Acc) error {
+	core.Lock()
+	defer core.Unlock()
+	return core.Status(int(C.H5Pset_fapl_family(C.hid_t(self),
+		C.hsize_t(size), C.hid_t(member))),
+		"setting family driver")
+}
+
+// Gets the parameters of the family driver (see SetFamily). The
+// access properties of the members should be closed after use.
+// Wraps the H5Pget_fapl_family function
+func (self Acc) GetFamily() (size int, member Acc, err error) {
+	core.Lock()
+	defer core.Unlock()
+	var sze C.hsize_t
+	var memb C.hid_t
+	err = core.Status(int(C.H5Pget_fapl_family(C.hid_t(self), &sze,
+		&memb)), "getting family driver parameters")
+	if err == nil {
+		core.Track(core.Id(memb))
+	}
+	return int(sze), Acc(memb), err
+}
+
+// Uses the split driver, which stores the metadata and the raw data
+// in two files, whose names are the path of the file followed by the
+// given extensions (e.g. "-m.h5" and "-r.h5"). Each file is accessed
+// using its own properties, which can be DefaultAccess.
+// Wraps the H5Pset_fapl_split function
+func (self Acc) SetSplit(metaExt string, meta Acc, rawExt string, raw Acc) error {
+	core.Lock()
+	defer core.Unlock()
+	cmeta := C.CString(metaExt)
+	defer C.free(unsafe.Pointer(cmeta))
+	craw := C.CString(rawExt)
+	defer C.free(unsafe.Pointer(craw))
+	return core.Status(int(C.H5Pset_fapl_split(C.hid_t(self),
+		cmeta, C.hid_t(meta), craw, C.hid_t(raw))),
+		"setting split driver")
+}
+
+// Gets the extensions of the metadata and raw data files of the split
+// driver (see SetSplit), which is a special case of the multi driver
+// Wraps the H5Pget_fapl_multi function
+func (self Acc) GetSplit() (metaExt, rawExt string, err error) {
+	core.Lock()
+	defer core.Unlock()
+	var mapping [C.H5FD_MEM_NTYPES]C.H5FD_mem_t
+	var fapls [C.H5FD_MEM_NTYPES]C.hid_t
+	var names [C.H5FD_MEM_NTYPES]*C.char
+	var addrs [C.H5FD_MEM_NTYPES]C.haddr_t
+	var relax C.hbool_t
+	if err = core.Status(int(C.H5Pget_fapl_multi(C.hid_t(self),
+		&mapping[0], &fapls[0], &names[0], &addrs[0], &relax)),
+		"getting multi driver parameters"); err != nil {
+		return "", "", err
+	}
+	for i := range names {
+		if fapls[i] >= 0 {
+			C.H5Pclose(fapls[i])
+		}
+		if names[i] == nil {
+			continue
+		}
+		name := strings.TrimPrefix(C.GoString(names[i]), "%s")
+		switch i {
+		case C.H5FD_MEM_SUPER:
+			metaExt = name
+		case C.H5FD_MEM_DRAW:
+			rawExt = name
+		}
+		C.H5free_memory(unsafe.Pointer(names[i]))
+	}
+	return metaExt, rawExt, nil
+}
+
+// The types of operations logged by the log driver
+type LogFlag uint64
+
+const (
+	LOG_LOC_IO  LogFlag = C.H5FD_LOG_LOC_IO  // Location of the reads, writes and seeks
+	LOG_FILE_IO LogFlag = C.H5FD_LOG_FILE_IO // Number of times each byte is read or written
+	LOG_FLAVOR  LogFlag = C.H5FD_LOG_FLAVOR  // Type of data stored at each byte
+	LOG_NUM_IO  LogFlag = C.H5FD_LOG_NUM_IO  // Number of reads, writes, seeks and truncates
+	LOG_TIME_IO LogFlag = C.H5FD_LOG_TIME_IO // Time spent in each operation
+	LOG_ALLOC   LogFlag = C.H5FD_LOG_ALLOC   // Allocations of space in the file
+	LOG_FREE    LogFlag = C.H5FD_LOG_FREE    // Releases of space in the file
+	LOG_ALL     LogFlag = C.H5FD_LOG_ALL     // Everything
+)
+
+// Uses the log driver, which accesses the file like the sec2 driver
+// while logging the operations selected by the flags to the given
+// file (or to the standard error if it is empty). The size of the
+// buffer is only used with LOG_FILE_IO or LOG_FLAVOR, and should then
+// be the size of the file.
+// Wraps the H5Pset_fapl_log function
+func (self Acc) SetLog(logfile string, flags LogFlag, bufsize int) error {
+	core.Lock()
+	defer core.Unlock()
+	var clog *C.char
+	if logfile != "" {
+		clog = C.CString(logfile)
+		defer C.free(unsafe.Pointer(clog))
+	}
+	return core.Status(int(C.H5Pset_fapl_log(C.hid_t(self), clog,
+		C.ulonglong(flags), C.size_t(bufsize))),
+		"setting log driver")
+}
@@ -71,12 +71,16 @@ func (self Crt) Copy() (Crt, error) {
 // Close degree is given by the constants of
 // type CloseDegree
 func (self Crt) SetFClose(degree CloseDegree) error {
+	core.Lock()
+	defer core.Unlock()
 	return core.Status(int(C.H5Pset_fclose_degree(C.hid_t(self),
 		degree.C())), "setting close degree property")
 }
 
 // Gets the close degree of the file creation
 func (self Crt) GetFClose() (CloseDegree, error) {
+	core.Lock()
+	defer core.Unlock()
 	out := new(C.H5F_close_degree_t)
 	err := core.Status(int(C.H5Pget_fclose_degree(C.hid_t(self),
 		out)), "getting close degree property")
@@ -116,6 +120,8 @@ func (self Acc) Copy() (Acc, error) {
 // policy: The chunk preemption policy for all datasets. This must be between 0 and 1 inclusive and indicates the weighting according to which chunks which have been fully read or written are penalized when determining which chunks to flush from cache. A value of 0 means fully read or written chunks are treated no differently than other chunks (the preemption is strictly LRU) while a value of 1 means fully read or written chunks are always preempted before other chunks. If your application only reads or writes data once, this can be safely set to 1. Otherwise, this should be set lower depending on how often you re-read or re-write the same data.
 // The default value is 0.75. If the value passed is H5D_CHUNK_CACHE_W0_DEFAULT, then the property will not be set on dapl_id, and the parameter will come from the file access property list.
 func (self Acc) SetCache(nslots int, binsize int, policy float32) error {
+	core.Lock()
+	defer core.Unlock()
 	return core.Status(int(C.H5Pset_cache(C.hid_t(self),
 		0, // Deprecated parameter -> passing 0
 		C.size_t(nslots),
@@ -127,6 +133,8 @@ func (self Acc) SetCache(nslots int, binsize int, policy float32) error {
 // Returns the cache parameters, using the same meaning for
 // parameters as in the SetCache function
 func (self Acc) GetCache() (nslots int, binsize int, policy float32, err error) {
+	core.Lock()
+	defer core.Unlock()
 	var n, b *C.size_t
 	p := new(C.double)
 	err = core.Status(int(C.H5Pget_cache(C.hid_t(self),
@@ -142,6 +150,41 @@ func (self Acc) GetCache() (nslots int, binsize int, policy float32, err error)
 	return
 }
 
+// The versions of the file format
+type Libver int
+
+const (
+	LIBVER_EARLIEST Libver = C.H5F_LIBVER_EARLIEST // Most compatible format
+	LIBVER_V18      Libver = C.H5F_LIBVER_V18      // Format of 1.8
+	LIBVER_V110     Libver = C.H5F_LIBVER_V110     // Format of 1.10 (required by SWMR)
+	LIBVER_V112     Libver = C.H5F_LIBVER_V112     // Format of 1.12
+	LIBVER_LATEST   Libver = C.H5F_LIBVER_LATEST   // Latest format of the library
+)
+
+// Sets the range of versions of the file format used for the
+// objects created. Single-writer/multiple-readers (SWMR) access
+// requires a lower bound of at least LIBVER_V110 (typically
+// LIBVER_LATEST for both)
+// Wraps the H5Pset_libver_bounds function
+func (self Acc) SetLibverBounds(low, high Libver) error {
+	core.Lock()
+	defer core.Unlock()
+	return core.Status(int(C.H5Pset_libver_bounds(C.hid_t(self),
+		C.H5F_libver_t(low), C.H5F_libver_t(high))),
+		"setting library version bounds")
+}
+
+// Gets the range of versions of the file format (see SetLibverBounds)
+// Wraps the H5Pget_libver_bounds function
+func (self Acc) GetLibverBounds() (low, high Libver, err error) {
+	core.Lock()
+	defer core.Unlock()
+	var l, h C.H5F_libver_t
+	err = core.Status(int(C.H5Pget_libver_bounds(C.hid_t(self), &l, &h)),
+		"getting library version bounds")
+	return Libver(l), Libver(h), err
+}
+
 // Represents an HDF5 Id specifically for a file object
 type File core.Id
 
@@ -173,6 +216,19 @@ func (F File) Close() error {
 	return nil
 }
 
+// Switches the file, opened for writing, to single-writer/multiple-
+// readers mode: other processes can then open it with SWMR_READ while
+// it is being written. The file must use the latest format (see
+// Acc.SetLibverBounds), and no new groups, datasets or attributes
+// can be created afterwards, although datasets can still be extended.
+// Wraps the H5Fstart_swmr_write function
+func (F File) StartSWMRWrite() error {
+	core.Lock()
+	defer core.Unlock()
+	return core.Status(int(C.H5Fstart_swmr_write(C.hid_t(F))),
+		"starting SWMR write mode of fileid=%v", F)
+}
+
 // Represents a flag for accessing a file
 type Flag uint
 
@@ -190,6 +246,11 @@ const (
 	EXCL   Flag = 4  // Raises an exception if file exists
 	DEBUG  Flag = 8  // Prints debug info
 	CREATE Flag = 16 // Create new file, raises an exception if file exists
+	// Opens the file for writing while it is read by others (SWMR)
+	SWMR_WRITE Flag = 32
+	// Opens the file for reading while it is written by another
+	// process (SWMR)
+	SWMR_READ Flag = 64
 )
 
 // Represents the default value for the flag, ignoring
@@ -199,11 +260,14 @@ const DEFAULT Flag = 0xffffff
 // If the Id is <0, returns some error. Otherwise, simply returns
 // the Id of the file, and a nil error
 func try(id File, msg string, args ...interface{}) (File, error) {
+	core.Track(core.Id(id))
 	return id, core.Status(int(id), fmt.Sprintf(msg, args...))
 }
 
 // Wraps the H5Fcreate function
 func Create(path string, flag Flag, c Crt, a Acc) (File, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(File(C.H5Fcreate(C.CString(path),
 		C.unsigned(flag),
 		C.hid_t(c.Id()),
@@ -213,6 +277,8 @@ func Create(path string, flag Flag, c Crt, a Acc) (File, error) {
 
 // Wraps the H5Fopen function
 func Open(path string, flag Flag, a Acc) (File, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(File(C.H5Fopen(C.CString(path),
 		C.unsigned(flag),
 		C.hid_t(a.Id()))),
@@ -221,12 +287,16 @@ func Open(path string, flag Flag, a Acc) (File, error) {
 
 // Wraps the H5Freopen function, reopening the file with a new Id
 func Reopen(id File) (File, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(File(C.H5Freopen(C.hid_t(id))),
 		"reopening fileid=%v", id)
 }
 
 // Closes the file, wrapping H5Fclose
 func Close(id File) error {
+	core.Lock()
+	defer core.Unlock()
 	return core.Status(int(C.H5Fclose(C.hid_t(id))),
 		"closing fileid=%v", id)
 }
@@ -235,6 +305,8 @@ func Close(id File) error {
 // The `global` boolean parameter states whether the scope should
 // be H5F_SCOPE_LOCAL (global=false) or H5F_SCOPE_GLOBAL (global=true)
 func Flush(fileid File, global bool) error {
+	core.Lock()
+	defer core.Unlock()
 	scope := C.H5F_SCOPE_LOCAL
 	if global {
 		scope = C.H5F_SCOPE_GLOBAL
@@ -260,6 +332,21 @@ const (
 // C representation of the type
 func (self Kind) C() C.unsigned { return C.unsigned(self) }
 
+// The name of the type
+func (self Kind) String() string {
+	switch self {
+	case FileType:
+		return "file"
+	case DatasetType:
+		return "dataset"
+	case GroupType:
+		return "group"
+	case AttributeType:
+		return "attribute"
+	}
+	return fmt.Sprintf("kind %d", uint(self))
+}
+
 // All the types
 var all_kinds = []Kind{FileType, DatasetType,
 	GroupType, AttributeType}
@@ -288,9 +375,17 @@ func GetAll(file File) ([]Object, error) {
 	return out, nil
 }
 
+// Gets the objects which are currently open in _all_ the files,
+// including the files themselves
+func OpenObjects() ([]Object, error) {
+	return GetAll(File(C.H5F_OBJ_ALL))
+}
+
 // Gets _all_ the IDs in the file for the given type(s),
 // wrapping both the H5Fget_obj_count and H5Fget_obj_ids functions
 func GetIds(file File, typ Kind) ([]core.Id, error) {
+	core.Lock()
+	defer core.Unlock()
 	f := C.hid_t(file)
 	types := (typ | Local).C()
 	nb := C.H5Fget_obj_count(f, types)
@@ -0,0 +1,105 @@
+package h5f
+
+/*
+#include <stdlib.h>
+#include <hdf5.h>
+*/
+import "C"
+import (
+	"fmt"
+	"sync/atomic"
+	"unsafe"
+)
+import (
+	"github.com/valoox/h5go/core"
+)
+
+// Sets the initial content of the file, which is copied. This is
+// used with the core driver to open a file from its image in memory
+// (see OpenImage).
+// Wraps the H5Pset_file_image function
+func (self Acc) SetFileImage(image []byte) error {
+	core.Lock()
+	defer core.Unlock()
+	var ptr unsafe.Pointer
+	if len(image) > 0 {
+		ptr = unsafe.Pointer(&image[0])
+	}
+	return core.Status(int(C.H5Pset_file_image(C.hid_t(self), ptr,
+		C.size_t(len(image)))),
+		"setting file image")
+}
+
+// Gets a copy of the initial content of the file (see SetFileImage)
+// Wraps the H5Pget_file_image function
+func (self Acc) GetFileImage() ([]byte, error) {
+	core.Lock()
+	defer core.Unlock()
+	var ptr unsafe.Pointer
+	var size C.size_t
+	if err := core.Status(int(C.H5Pget_file_image(C.hid_t(self),
+		&ptr, &size)), "getting file image"); err != nil {
+		return nil, err
+	}
+	if ptr == nil {
+		return nil, nil
+	}
+	defer C.H5free_memory(ptr)
+	return C.GoBytes(ptr, C.int(size)), nil
+}
+
+// Gets the content of the file, as it would be written to the disk.
+// The file is flushed beforehand, and can be of any driver.
+// Wraps the H5Fget_file_image function
+func (F File) Image() ([]byte, error) {
+	core.Lock()
+	defer core.Unlock()
+	if err := F.Flush(); err != nil {
+		return nil, err
+	}
+	size := int(C.H5Fget_file_image(C.hid_t(F), nil, 0))
+	if err := core.Status(size, "getting size of file image"); err != nil {
+		return nil, err
+	}
+	out := make([]byte, size)
+	if size == 0 {
+		return out, nil
+	}
+	n := int(C.H5Fget_file_image(C.hid_t(F), unsafe.Pointer(&out[0]),
+		C.size_t(size)))
+	return out[:n], core.Status(n, "getting file image")
+}
+
+// Counts the images opened, to give them distinct names
+var images int64
+
+// Opens a file from its image in memory (see File.Image), with the
+// core driver and no backing store: the image is copied, and the
+// changes made to the file (if opened with RW) are only kept in
+// memory. The access property list can be DefaultAccess, otherwise
+// its driver is replaced.
+func OpenImage(image []byte, flag Flag, a Acc) (File, error) {
+	var acc Acc
+	var err error
+	if a == DefaultAccess {
+		acc, err = Access()
+	} else {
+		acc, err = a.Copy()
+	}
+	if err != nil {
+		return -1, err
+	}
+	defer acc.Close()
+	increment := len(image)
+	if increment < 64*core.KB {
+		increment = 64 * core.KB
+	}
+	if err := acc.SetCore(increment, false); err != nil {
+		return -1, err
+	}
+	if err := acc.SetFileImage(image); err != nil {
+		return -1, err
+	}
+	name := fmt.Sprintf("image-%d", atomic.AddInt64(&images, 1))
+	return Open(name, flag, acc)
+}
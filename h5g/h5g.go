@@ -10,6 +10,7 @@ import "C"
 import (
 	"github.com/valoox/h5go/core"
 	"github.com/valoox/h5go/h5l"
+	"github.com/valoox/h5go/h5o"
 	"github.com/valoox/h5go/h5p"
 )
 
@@ -49,6 +50,38 @@ func (self Crt) Class() h5p.Class { return h5p.GROUP_CREATE }
 // Disposes of the resource
 func (self Crt) Close() error { return h5p.Close(self.Id()) }
 
+// Sets whether the creation order of the links in the group is
+// tracked, and whether it is indexed. Tracking is required to
+// iterate over the links in creation order (core.ByCreation)
+// Wraps the H5Pset_link_creation_order function
+func (self Crt) SetLinkOrder(tracked, indexed bool) error {
+	core.Lock()
+	defer core.Unlock()
+	var flags C.unsigned
+	if tracked {
+		flags |= C.H5P_CRT_ORDER_TRACKED
+	}
+	if indexed {
+		flags |= C.H5P_CRT_ORDER_TRACKED | C.H5P_CRT_ORDER_INDEXED
+	}
+	return core.Status(int(C.H5Pset_link_creation_order(
+		C.hid_t(self), flags)), "setting link creation order")
+}
+
+// Gets whether the creation order of the links is tracked and
+// indexed
+// Wraps the H5Pget_link_creation_order function
+func (self Crt) GetLinkOrder() (tracked, indexed bool, err error) {
+	core.Lock()
+	defer core.Unlock()
+	var flags C.unsigned
+	err = core.Status(int(C.H5Pget_link_creation_order(
+		C.hid_t(self), &flags)), "getting link creation order")
+	tracked = flags&C.H5P_CRT_ORDER_TRACKED != 0
+	indexed = flags&C.H5P_CRT_ORDER_INDEXED != 0
+	return
+}
+
 // Class for group access
 type Acc h5p.Property
 
@@ -85,14 +118,55 @@ func (g Group) Close() error {
 	return Close(g)
 }
 
+// The names of the links in the group, in alphanumerical order
+func (g Group) Keys() ([]string, error) {
+	return Keys(g, core.ByName, core.Increasing)
+}
+
+// Calls the function on each link of the group
+// (see h5l.Iterate)
+func (g Group) Iterate(idx core.Index, order core.Order,
+	fn h5l.IterFunc) error {
+	return h5l.Iterate(g, idx, order, fn)
+}
+
+// Recursively calls the function on all the links reachable from
+// the group (see h5l.Visit)
+func (g Group) Visit(idx core.Index, order core.Order,
+	fn h5l.IterFunc) error {
+	return h5l.Visit(g, idx, order, fn)
+}
+
+// Recursively calls the function on all the objects reachable from
+// the group (see h5o.Visit)
+func (g Group) VisitObjects(idx core.Index, order core.Order,
+	fn h5o.VisitFunc) error {
+	return h5o.Visit(g, idx, order, fn)
+}
+
+// The names of the links at the location (a group or the root group
+// of a file), following the index in the given order
+func Keys(at core.Location, idx core.Index, order core.Order) ([]string, error) {
+	out := make([]string, 0, 16)
+	err := h5l.Iterate(at, idx, order,
+		func(name string, _ h5l.Info) error {
+			out = append(out, name)
+			return nil
+		})
+	return out, err
+}
+
 // Returns a Group, raising an error if something is wrong
 func try(id Group, ctxt string, args ...interface{}) (Group, error) {
+	core.Track(core.Id(id))
 	return id, core.Status(int(id), ctxt, args...)
 }
 
 // Wraps the H5Gcreate function
 func Create(root core.Location, path core.Path, links h5l.Crt,
 	create Crt, access Acc) (Group, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(Group(C.H5Gcreate2(C.hid_t(root.At()),
 		C.CString(path.String()), C.hid_t(links),
 		C.hid_t(create), C.hid_t(access))),
@@ -101,12 +175,16 @@ func Create(root core.Location, path core.Path, links h5l.Crt,
 
 // Wraps the H5Gclose function
 func Close(group Group) error {
+	core.Lock()
+	defer core.Unlock()
 	return core.Status(int(C.H5Gclose(C.hid_t(group))),
 		"closing group")
 }
 
 // Wraps the H5Gopen function
 func Open(at core.Location, pth core.Path, acc Acc) (Group, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(Group(C.H5Gopen2(C.hid_t(at.At()),
 		C.CString(pth.String()), C.hid_t(acc))),
 		"opening group at %s", pth)
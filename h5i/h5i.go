@@ -7,6 +7,7 @@ package h5i
 #include <hdf5.h>
 */
 import "C"
+import "unsafe"
 import (
 	"github.com/valoox/h5go/core"
 )
@@ -14,13 +15,17 @@ import (
 // Returns the path of an object in a file
 // Wraps the H5Iget_name function
 func GetName(id core.Id) (core.Path, error) {
-	var out *C.char
-	sze := C.H5Iget_name(C.hid_t(id), out, 1)
+	core.Lock()
+	defer core.Unlock()
+	sze := C.H5Iget_name(C.hid_t(id), nil, 0)
 	if err := core.Status(int(sze), "getting name"); err != nil {
 		return "", err
-	} else if err := core.Status(int(C.H5Iget_name(C.hid_t(id),
-		out, C.size_t(sze+1))), "getting name"); err != nil {
+	}
+	out := make([]byte, int(sze)+1)
+	if err := core.Status(int(C.H5Iget_name(C.hid_t(id),
+		(*C.char)(unsafe.Pointer(&out[0])), C.size_t(sze+1))),
+		"getting name"); err != nil {
 		return "", err
 	}
-	return core.Path(C.GoString(out)), nil
+	return core.Path(out[:sze]), nil
 }
@@ -4,12 +4,14 @@ package h5l
 
 /*
 #cgo LDFLAGS: -lhdf5
+#include <stdlib.h>
 #include <hdf5.h>
 */
 import "C"
 
 import (
 	"fmt"
+	"unsafe"
 
 	"github.com/valoox/h5go/core"
 	"github.com/valoox/h5go/h5p"
@@ -55,6 +57,58 @@ func (self Acc) Copy() (Acc, error) {
 	return Acc(id), err
 }
 
+// Sets the prefix prepended to the file names of external links
+// when they are traversed
+// Wraps the H5Pset_elink_prefix function
+func (self Acc) SetELinkPrefix(prefix string) error {
+	core.Lock()
+	defer core.Unlock()
+	cp := C.CString(prefix)
+	defer C.free(unsafe.Pointer(cp))
+	return core.Status(int(C.H5Pset_elink_prefix(C.hid_t(self), cp)),
+		"setting external link prefix")
+}
+
+// Gets the prefix prepended to the file names of external links
+// Wraps the H5Pget_elink_prefix function
+func (self Acc) GetELinkPrefix() (string, error) {
+	core.Lock()
+	defer core.Unlock()
+	sze := C.H5Pget_elink_prefix(C.hid_t(self), nil, 0)
+	if err := core.Status(int(sze),
+		"getting external link prefix"); err != nil || sze == 0 {
+		return "", err
+	}
+	out := make([]C.char, int(sze)+1)
+	if err := core.Status(int(C.H5Pget_elink_prefix(C.hid_t(self),
+		&out[0], C.size_t(len(out)))),
+		"getting external link prefix"); err != nil {
+		return "", err
+	}
+	return C.GoString(&out[0]), nil
+}
+
+// Sets the maximum number of soft or user-defined (including
+// external) links which can be traversed when resolving a path
+// Wraps the H5Pset_nlinks function
+func (self Acc) SetNLinks(n int) error {
+	core.Lock()
+	defer core.Unlock()
+	return core.Status(int(C.H5Pset_nlinks(C.hid_t(self),
+		C.size_t(n))), "setting maximum link traversals")
+}
+
+// Gets the maximum number of links traversed when resolving a path
+// Wraps the H5Pget_nlinks function
+func (self Acc) GetNLinks() (int, error) {
+	core.Lock()
+	defer core.Unlock()
+	var n C.size_t
+	err := core.Status(int(C.H5Pget_nlinks(C.hid_t(self), &n)),
+		"getting maximum link traversals")
+	return int(n), err
+}
+
 // Represents the Id of a link
 type Link core.Id
 
@@ -67,6 +121,8 @@ func try(id Link, msg string, args ...interface{}) (Link, error) {
 func Hard(src core.Location, name core.Path,
 	link core.Location, lname core.Path,
 	create Crt, access Acc) (Link, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(Link(C.H5Lcreate_hard(C.hid_t(src.At()),
 		C.CString(name.String()), C.hid_t(link.At()),
 		C.CString(lname.String()),
@@ -77,16 +133,36 @@ func Hard(src core.Location, name core.Path,
 // Wraps the H5Lcreate_soft function
 func Soft(target core.Path, loc core.Location, link core.Path,
 	create Crt, access Acc) (Link, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(Link(C.H5Lcreate_soft(C.CString(target.String()),
 		C.hid_t(loc.At()), C.CString(link.String()),
 		C.hid_t(create), C.hid_t(access))),
 		"creating hard link from %s to %s", target, link)
 }
 
+// Wraps the H5Lcreate_external function
+func External(file string, target core.Path, loc core.Location,
+	link core.Path, create Crt, access Acc) (Link, error) {
+	core.Lock()
+	defer core.Unlock()
+	cf, ct, cl := C.CString(file), C.CString(target.String()),
+		C.CString(link.String())
+	defer C.free(unsafe.Pointer(cf))
+	defer C.free(unsafe.Pointer(ct))
+	defer C.free(unsafe.Pointer(cl))
+	return try(Link(C.H5Lcreate_external(cf, ct,
+		C.hid_t(loc.At()), cl,
+		C.hid_t(create), C.hid_t(access))),
+		"creating external link from %s to %s:%s", link, file, target)
+}
+
 // Wraps the H5Lcopy function
 func Copy(src core.Location, rel core.Path,
 	dest core.Location, drel core.Path,
 	create Crt, access Acc) (Link, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(Link(C.H5Lcopy(C.hid_t(src.At()),
 		C.CString(rel.String()), C.hid_t(dest.At()),
 		C.CString(drel.String()),
@@ -98,6 +174,8 @@ func Copy(src core.Location, rel core.Path,
 func Move(src core.Location, rel core.Path,
 	dest core.Location, drel core.Path,
 	create Crt, access Acc) (Link, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(Link(C.H5Lmove(C.hid_t(src.At()),
 		C.CString(rel.String()), C.hid_t(dest.At()),
 		C.CString(drel.String()),
@@ -107,7 +185,116 @@ func Move(src core.Location, rel core.Path,
 
 // Wraps the H5Ldelete function
 func Delete(src core.Location, name core.Path, prop Acc) error {
+	core.Lock()
+	defer core.Unlock()
 	return core.Status(int(C.H5Ldelete(C.hid_t(src.At()),
 		C.CString(name.String()), C.hid_t(prop))),
 		"deleting link %s", name)
 }
+
+// The type of a link
+type Kind int
+
+// The different types of links
+const (
+	HARD     Kind = C.H5L_TYPE_HARD     // Hard link to an object
+	SOFT     Kind = C.H5L_TYPE_SOFT     // Soft link to a path
+	EXTERNAL Kind = C.H5L_TYPE_EXTERNAL // Link to an object in another file
+)
+
+// Describes a link
+type Info struct {
+	// The type of the link
+	Type Kind
+	// Whether the creation order is valid
+	CorderValid bool
+	// The creation order of the link
+	Corder int64
+	// Whether the name of the link is UTF-8 encoded
+	UTF8 bool
+	// The token of the target object, for hard links
+	Token core.Token
+	// The size of the value of soft and external links
+	ValSize int
+}
+
+// Converts the C description of the link
+func newInfo(info *C.H5L_info2_t) Info {
+	out := Info{
+		Type:        Kind(info._type),
+		CorderValid: bool(info.corder_valid),
+		Corder:      int64(info.corder),
+		UTF8:        info.cset == C.H5T_CSET_UTF8,
+	}
+	if out.Type == HARD {
+		out.Token = core.Token(info.u)
+	} else {
+		out.ValSize = int(*(*C.size_t)(unsafe.Pointer(&info.u[0])))
+	}
+	return out
+}
+
+// Checks whether the link exists. All the intermediate links of the
+// path must exist
+// Wraps the H5Lexists function
+func Exists(at core.Location, name core.Path, access Acc) (bool, error) {
+	core.Lock()
+	defer core.Unlock()
+	cn := C.CString(name.String())
+	defer C.free(unsafe.Pointer(cn))
+	res := C.H5Lexists(C.hid_t(at.At()), cn, C.hid_t(access))
+	return res > 0, core.Status(int(res),
+		"checking existence of link %s", name)
+}
+
+// Gets the description of the link
+// Wraps the H5Lget_info2 function
+func GetInfo(at core.Location, name core.Path, access Acc) (Info, error) {
+	core.Lock()
+	defer core.Unlock()
+	cn := C.CString(name.String())
+	defer C.free(unsafe.Pointer(cn))
+	var info C.H5L_info2_t
+	if err := core.Status(int(C.H5Lget_info2(C.hid_t(at.At()), cn,
+		&info, C.hid_t(access))),
+		"getting info of link %s", name); err != nil {
+		return Info{}, err
+	}
+	return newInfo(&info), nil
+}
+
+// Gets the target of a soft or external link. For soft links, the
+// file is empty and the path is the target of the link. For
+// external links, these are the file and the path in that file.
+// Wraps the H5Lget_val and H5Lunpack_elink_val functions
+func Value(at core.Location, name core.Path, access Acc) (file string, target core.Path, err error) {
+	core.Lock()
+	defer core.Unlock()
+	info, err := GetInfo(at, name, access)
+	if err != nil {
+		return "", "", err
+	}
+	if info.Type == HARD {
+		return "", "", fmt.Errorf("%s is a hard link", name)
+	}
+	cn := C.CString(name.String())
+	defer C.free(unsafe.Pointer(cn))
+	buf := make([]byte, info.ValSize+1)
+	if err = core.Status(int(C.H5Lget_val(C.hid_t(at.At()), cn,
+		unsafe.Pointer(&buf[0]), C.size_t(len(buf)),
+		C.hid_t(access))),
+		"getting value of link %s", name); err != nil {
+		return "", "", err
+	}
+	if info.Type == SOFT {
+		return "", core.Path(C.GoString((*C.char)(unsafe.Pointer(&buf[0])))), nil
+	}
+	var cfile, cpath *C.char
+	if err = core.Status(int(C.H5Lunpack_elink_val(
+		unsafe.Pointer(&buf[0]), C.size_t(info.ValSize),
+		nil, &cfile, &cpath)),
+		"unpacking external link %s", name); err != nil {
+		return "", "", err
+	}
+	return C.GoString(cfile), core.Path(C.GoString(cpath)), nil
+}
@@ -0,0 +1,23 @@
+#include <stdint.h>
+#include <hdf5.h>
+#include "_cgo_export.h"
+
+// Forwards the links to the Go callback
+static herr_t link_cb(hid_t group, const char *name,
+		      const H5L_info2_t *info, void *data) {
+  return goLinkCallback((char *)name, (H5L_info2_t *)info,
+			(uintptr_t)data);
+}
+
+// Iterates over the links of the group
+herr_t h5l_iterate(hid_t group, H5_index_t idx, H5_iter_order_t order,
+		   uintptr_t h) {
+  hsize_t i = 0;
+  return H5Literate2(group, idx, order, &i, link_cb, (void *)h);
+}
+
+// Recursively visits the links of the group
+herr_t h5l_visit(hid_t group, H5_index_t idx, H5_iter_order_t order,
+		 uintptr_t h) {
+  return H5Lvisit2(group, idx, order, link_cb, (void *)h);
+}
@@ -0,0 +1,81 @@
+package h5l
+
+/*
+#include <stdint.h>
+#include <hdf5.h>
+
+herr_t h5l_iterate(hid_t, H5_index_t, H5_iter_order_t, uintptr_t);
+herr_t h5l_visit(hid_t, H5_index_t, H5_iter_order_t, uintptr_t);
+*/
+import "C"
+import "runtime/cgo"
+
+import "github.com/valoox/h5go/core"
+
+// The function called for each link during an iteration, with the
+// name (or path relative to the root, when visiting) of the link and
+// its description. Returning core.Stop ends the iteration early,
+// while any other error aborts it and is returned by the iteration.
+type IterFunc func(name string, info Info) error
+
+// The state of an iteration, shared with the C callback
+type iteration struct {
+	fn  IterFunc // The Go callback
+	err error    // The error returned by the callback, if any
+}
+
+// Called by the library for each link
+//
+//export goLinkCallback
+func goLinkCallback(name *C.char, info *C.H5L_info2_t, h C.uintptr_t) C.herr_t {
+	it := cgo.Handle(h).Value().(*iteration)
+	switch err := it.fn(C.GoString(name), newInfo(info)); err {
+	case nil:
+		return 0
+	case core.Stop:
+		return 1
+	default:
+		it.err = err
+		return -1
+	}
+}
+
+// Runs the iteration, returning the error raised by the callback if
+// any, or the error of the library otherwise
+func run(fn IterFunc, call func(C.uintptr_t) C.herr_t,
+	context string, args ...interface{}) error {
+	it := &iteration{fn: fn}
+	h := cgo.NewHandle(it)
+	defer h.Delete()
+	res := call(C.uintptr_t(h))
+	if it.err != nil {
+		return it.err
+	}
+	return core.Status(int(res), context, args...)
+}
+
+// Calls the function on each of the links in the group, following
+// the index in the given order
+// Wraps the H5Literate2 function
+func Iterate(at core.Location, idx core.Index, order core.Order,
+	fn IterFunc) error {
+	core.Lock()
+	defer core.Unlock()
+	return run(fn, func(h C.uintptr_t) C.herr_t {
+		return C.h5l_iterate(C.hid_t(at.At()), C.H5_index_t(idx),
+			C.H5_iter_order_t(order), h)
+	}, "iterating over links")
+}
+
+// Recursively calls the function on all the links reachable from
+// the group, following the index in the given order.
+// Wraps the H5Lvisit2 function
+func Visit(at core.Location, idx core.Index, order core.Order,
+	fn IterFunc) error {
+	core.Lock()
+	defer core.Unlock()
+	return run(fn, func(h C.uintptr_t) C.herr_t {
+		return C.h5l_visit(C.hid_t(at.At()), C.H5_index_t(idx),
+			C.H5_iter_order_t(order), h)
+	}, "visiting links")
+}
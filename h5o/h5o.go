@@ -0,0 +1,253 @@
+// This wraps the H5O* family of functions, for manipulating the
+// objects of a file (groups, datasets and named datatypes) regardless
+// of their kind
+package h5o
+
+/*
+#cgo LDFLAGS: -lhdf5
+#include <stdlib.h>
+#include <hdf5.h>
+*/
+import "C"
+
+import (
+	"time"
+	"unsafe"
+)
+
+import (
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5l"
+	"github.com/valoox/h5go/h5p"
+)
+
+// The type of an object
+type Type int
+
+// The different types of objects
+const (
+	UNKNOWN  Type = C.H5O_TYPE_UNKNOWN        // Unknown object
+	GROUP    Type = C.H5O_TYPE_GROUP          // Group
+	DATASET  Type = C.H5O_TYPE_DATASET        // Dataset
+	DATATYPE Type = C.H5O_TYPE_NAMED_DATATYPE // Named datatype
+)
+
+// The name of the type of object
+func (self Type) String() string {
+	switch self {
+	case GROUP:
+		return "group"
+	case DATASET:
+		return "dataset"
+	case DATATYPE:
+		return "datatype"
+	}
+	return "unknown"
+}
+
+// Describes an object
+type Info struct {
+	// The number of the file the object belongs to
+	FileNo uint64
+	// The token identifying the object in its file
+	Token core.Token
+	// The type of the object
+	Type Type
+	// The number of hard links to the object
+	Refs int
+	// The last access, modification, change and creation times,
+	// if recorded in the file
+	Accessed, Modified, Changed, Born time.Time
+	// The number of attributes attached to the object
+	NumAttrs int
+}
+
+// Converts a C timestamp, which is zero when not recorded
+func stamp(t C.time_t) time.Time {
+	if t == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(t), 0)
+}
+
+// Converts the C description of the object
+func newInfo(info *C.H5O_info2_t) Info {
+	return Info{
+		FileNo:   uint64(info.fileno),
+		Token:    *(*core.Token)(unsafe.Pointer(&info.token)),
+		Type:     Type(info._type),
+		Refs:     int(info.rc),
+		Accessed: stamp(info.atime),
+		Modified: stamp(info.mtime),
+		Changed:  stamp(info.ctime),
+		Born:     stamp(info.btime),
+		NumAttrs: int(info.num_attrs),
+	}
+}
+
+// Default object copy property list
+const DefaultCopy = Cpy(h5p.Default)
+
+// Creates a new object copy property list
+func Copying() (Cpy, error) {
+	id, err := h5p.Create(h5p.OBJECT_COPY)
+	return Cpy(id), err
+}
+
+// Object copy property list
+type Cpy h5p.Property
+
+// The Property list ID
+func (self Cpy) Id() h5p.Property { return h5p.Property(self) }
+
+// Copies the property list
+func (self Cpy) Copy() (Cpy, error) {
+	id, err := h5p.Copy(self.Id())
+	return Cpy(id), err
+}
+
+// The class of the property list
+func (self Cpy) Class() h5p.Class { return h5p.OBJECT_COPY }
+
+// Disposes of the resource
+func (self Cpy) Close() error { return h5p.Close(self.Id()) }
+
+// The options for copying objects, which can be combined
+type CopyFlag uint
+
+const (
+	// Only copies the immediate members of a group
+	SHALLOW CopyFlag = C.H5O_COPY_SHALLOW_HIERARCHY_FLAG
+	// Copies the targets of soft links instead of the links
+	EXPAND_SOFT CopyFlag = C.H5O_COPY_EXPAND_SOFT_LINK_FLAG
+	// Copies the targets of external links instead of the links
+	EXPAND_EXT CopyFlag = C.H5O_COPY_EXPAND_EXT_LINK_FLAG
+	// Copies the objects pointed by references
+	EXPAND_REF CopyFlag = C.H5O_COPY_EXPAND_REFERENCE_FLAG
+	// Copies the objects without their attributes
+	WITHOUT_ATTR CopyFlag = C.H5O_COPY_WITHOUT_ATTR_FLAG
+	// Reuses the committed datatypes already in the destination
+	MERGE_DTYPES CopyFlag = C.H5O_COPY_MERGE_COMMITTED_DTYPE_FLAG
+)
+
+// Sets the options for copying objects
+// Wraps the H5Pset_copy_object function
+func (self Cpy) SetFlags(flags CopyFlag) error {
+	core.Lock()
+	defer core.Unlock()
+	return core.Status(int(C.H5Pset_copy_object(C.hid_t(self),
+		C.unsigned(flags))), "setting object copy options")
+}
+
+// Gets the options for copying objects
+// Wraps the H5Pget_copy_object function
+func (self Cpy) GetFlags() (CopyFlag, error) {
+	core.Lock()
+	defer core.Unlock()
+	var flags C.unsigned
+	err := core.Status(int(C.H5Pget_copy_object(C.hid_t(self),
+		&flags)), "getting object copy options")
+	return CopyFlag(flags), err
+}
+
+// Represents an Id to an object, whatever its type. Once its type is
+// known, the Id can be converted to the more specific types of the
+// other packages (e.g. h5g.Group(obj.Id()) for a GROUP)
+type Object core.Id
+
+// The HDF5 Id of the object
+func (o Object) Id() core.Id { return core.Id(o) }
+
+// Closes the object
+// Wraps the H5Oclose function
+func (o Object) Close() error {
+	core.Lock()
+	defer core.Unlock()
+	return core.Status(int(C.H5Oclose(C.hid_t(o))), "closing object")
+}
+
+// The description of the object
+func (o Object) Info() (Info, error) { return GetInfo(o) }
+
+// The type of the object
+func (o Object) Type() (Type, error) {
+	info, err := GetInfo(o)
+	return info.Type, err
+}
+
+// Converts the path for the C calls
+func cpath(p core.Path) *C.char { return C.CString(p.String()) }
+
+// Opens the object at the given path, whatever its type
+// Wraps the H5Oopen function
+func Open(at core.Location, path core.Path, acc h5l.Acc) (Object, error) {
+	core.Lock()
+	defer core.Unlock()
+	cp := cpath(path)
+	defer C.free(unsafe.Pointer(cp))
+	id := Object(C.H5Oopen(C.hid_t(at.At()), cp, C.hid_t(acc)))
+	core.Track(core.Id(id))
+	return id, core.Status(int(id), "opening object at %s", path)
+}
+
+// Checks whether an object exists at the given path. All the
+// intermediate links of the path must exist.
+// Wraps the H5Oexists_by_name function
+func Exists(at core.Location, path core.Path, acc h5l.Acc) (bool, error) {
+	core.Lock()
+	defer core.Unlock()
+	cp := cpath(path)
+	defer C.free(unsafe.Pointer(cp))
+	res := C.H5Oexists_by_name(C.hid_t(at.At()), cp, C.hid_t(acc))
+	return res > 0, core.Status(int(res),
+		"checking existence of object at %s", path)
+}
+
+// Gets the description of an open object
+// Wraps the H5Oget_info3 function
+func GetInfo(obj core.Object) (Info, error) {
+	core.Lock()
+	defer core.Unlock()
+	var info C.H5O_info2_t
+	if err := core.Status(int(C.H5Oget_info3(C.hid_t(obj.Id()),
+		&info, C.H5O_INFO_ALL)),
+		"getting object info"); err != nil {
+		return Info{}, err
+	}
+	return newInfo(&info), nil
+}
+
+// Gets the description of the object at the given path
+// Wraps the H5Oget_info_by_name3 function
+func GetInfoByName(at core.Location, path core.Path, acc h5l.Acc) (Info, error) {
+	core.Lock()
+	defer core.Unlock()
+	cp := cpath(path)
+	defer C.free(unsafe.Pointer(cp))
+	var info C.H5O_info2_t
+	if err := core.Status(int(C.H5Oget_info_by_name3(
+		C.hid_t(at.At()), cp, &info, C.H5O_INFO_ALL,
+		C.hid_t(acc))),
+		"getting info of object at %s", path); err != nil {
+		return Info{}, err
+	}
+	return newInfo(&info), nil
+}
+
+// Copies the object at the source path (and all its members for
+// groups, unless the SHALLOW option is set) to the destination path.
+// Source and destination can belong to different files
+// Wraps the H5Ocopy function
+func Copy(src core.Location, sname core.Path,
+	dst core.Location, dname core.Path,
+	cpy Cpy, links h5l.Crt) error {
+	core.Lock()
+	defer core.Unlock()
+	cs, cd := cpath(sname), cpath(dname)
+	defer C.free(unsafe.Pointer(cs))
+	defer C.free(unsafe.Pointer(cd))
+	return core.Status(int(C.H5Ocopy(C.hid_t(src.At()), cs,
+		C.hid_t(dst.At()), cd,
+		C.hid_t(cpy), C.hid_t(links))),
+		"copying object %s to %s", sname, dname)
+}
@@ -0,0 +1,17 @@
+#include <stdint.h>
+#include <hdf5.h>
+#include "_cgo_export.h"
+
+// Forwards the objects to the Go callback
+static herr_t object_cb(hid_t obj, const char *name,
+			const H5O_info2_t *info, void *data) {
+  return goObjectCallback((char *)name, (H5O_info2_t *)info,
+			  (uintptr_t)data);
+}
+
+// Recursively visits the objects
+herr_t h5o_visit(hid_t obj, H5_index_t idx, H5_iter_order_t order,
+		 uintptr_t h) {
+  return H5Ovisit3(obj, idx, order, object_cb, (void *)h,
+		   H5O_INFO_ALL);
+}
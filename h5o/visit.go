@@ -0,0 +1,60 @@
+package h5o
+
+/*
+#include <stdint.h>
+#include <hdf5.h>
+
+herr_t h5o_visit(hid_t, H5_index_t, H5_iter_order_t, uintptr_t);
+*/
+import "C"
+import "runtime/cgo"
+
+import "github.com/valoox/h5go/core"
+
+// The function called for each object during a visit, with the path
+// of the object relative to the root of the visit ("." for the root
+// itself) and its description. Returning core.Stop ends the visit
+// early, while any other error aborts it and is returned.
+type VisitFunc func(name string, info Info) error
+
+// The state of a visit, shared with the C callback
+type visit struct {
+	fn  VisitFunc // The Go callback
+	err error     // The error returned by the callback, if any
+}
+
+// Called by the library for each object
+//
+//export goObjectCallback
+func goObjectCallback(name *C.char, info *C.H5O_info2_t, h C.uintptr_t) C.herr_t {
+	v := cgo.Handle(h).Value().(*visit)
+	switch err := v.fn(C.GoString(name), newInfo(info)); err {
+	case nil:
+		return 0
+	case core.Stop:
+		return 1
+	default:
+		v.err = err
+		return -1
+	}
+}
+
+// Recursively calls the function on all the objects reachable from
+// the object, following the index in the given order. Unlike
+// h5l.Visit, each object is only visited once, even if several links
+// point to it.
+// Wraps the H5Ovisit3 function
+func Visit(at core.Object, idx core.Index, order core.Order,
+	fn VisitFunc) error {
+	core.Lock()
+	defer core.Unlock()
+	v := &visit{fn: fn}
+	h := cgo.NewHandle(v)
+	defer h.Delete()
+	res := C.h5o_visit(C.hid_t(at.Id()), C.H5_index_t(idx),
+		C.H5_iter_order_t(order), C.uintptr_t(h))
+	if v.err != nil {
+		return v.err
+	}
+	return core.Status(int(res), "visiting objects")
+}
@@ -84,6 +84,7 @@ var names = [...]string{
 
 // Returns the Id, raising an error if needed
 func try(id Property, ctxt string, params ...interface{}) (Property, error) {
+	core.Track(core.Id(id))
 	return id, core.Status(int(id), fmt.Sprintf(ctxt, params...))
 }
 
@@ -107,22 +108,30 @@ func (self Property) Class() Class {
 
 // Creates the object with the given class
 func Create(cls Class) (Property, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(Property(C.H5Pcreate(cls.C())), "creating property list")
 }
 
 // Copies the property list in C
 func Copy(id Property) (Property, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(Property(C.H5Pcopy(C.hid_t(id))),
 		"copying property list")
 }
 
 // Closes the property list in C
 func Close(id Property) error {
+	core.Lock()
+	defer core.Unlock()
 	return core.Status(int(C.H5Pclose(C.hid_t(id))), "closing property list")
 }
 
 // Gets the class of the C id
 func GetClass(id Property) (Class, error) {
+	core.Lock()
+	defer core.Unlock()
 	i := C.H5Pget_class(C.hid_t(id))
 	return Class(i), core.Status(int(i), "getting property list class")
 }
@@ -0,0 +1,202 @@
+// This wraps the H5R* family of functions, for creating and
+// dereferencing references to objects and to regions of datasets.
+// References are plain Go values, which can be stored in datasets
+// and attributes like any other value: they provide their own
+// datatype, which is picked up by h5t.Parse.
+package h5r
+
+/*
+#cgo LDFLAGS: -lhdf5
+#include <stdlib.h>
+#include <hdf5.h>
+
+// The reference types, which are only available at runtime
+static inline hid_t ref_obj() { return H5T_STD_REF_OBJ; }
+static inline hid_t ref_dsetreg() { return H5T_STD_REF_DSETREG; }
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+import (
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5d"
+	"github.com/valoox/h5go/h5g"
+	"github.com/valoox/h5go/h5o"
+	"github.com/valoox/h5go/h5s"
+	"github.com/valoox/h5go/h5t"
+)
+
+// The kind of reference
+type kind int
+
+const (
+	object kind = C.H5R_OBJECT
+	region kind = C.H5R_DATASET_REGION
+)
+
+// A reference to an object (group, dataset or named datatype)
+type Object C.hobj_ref_t
+
+// A reference to a region (selection) of a dataset
+type Region C.hdset_reg_ref_t
+
+// The datatype of object references
+func (Object) Type() (h5t.Datatype, error) {
+	core.Lock()
+	defer core.Unlock()
+	return h5t.Datatype(C.ref_obj()).Copy()
+}
+
+// The datatype of region references
+func (Region) Type() (h5t.Datatype, error) {
+	core.Lock()
+	defer core.Unlock()
+	return h5t.Datatype(C.ref_dsetreg()).Copy()
+}
+
+// Creates a reference, converting the name for the C call
+// Wraps the H5Rcreate function
+func create(ref unsafe.Pointer, at core.Location, name core.Path,
+	k kind, space h5s.Dataspace) error {
+	core.Lock()
+	defer core.Unlock()
+	cname := C.CString(name.String())
+	defer C.free(unsafe.Pointer(cname))
+	return core.Status(int(C.H5Rcreate(ref, C.hid_t(at.At()), cname,
+		C.H5R_type_t(k), C.hid_t(space))),
+		"creating reference to %s", name)
+}
+
+// Creates a reference to the object at the given path
+func NewObject(at core.Location, name core.Path) (Object, error) {
+	var ref Object
+	err := create(unsafe.Pointer(&ref), at, name, object, -1)
+	return ref, err
+}
+
+// Creates a reference to the region of the dataset at the given
+// path selected by the selection (an h5s.Hyperslab or h5s.Points
+// defined on the dataspace of the dataset)
+func NewRegion(at core.Location, name core.Path, sel h5s.Selection) (Region, error) {
+	var ref Region
+	err := create(unsafe.Pointer(&ref), at, name, region,
+		sel.Selection())
+	return ref, err
+}
+
+// Gets the type of the referenced object
+// Wraps the H5Rget_obj_type2 function
+func target(at core.Object, k kind, ref unsafe.Pointer) (h5o.Type, error) {
+	core.Lock()
+	defer core.Unlock()
+	var T C.H5O_type_t
+	err := core.Status(int(C.H5Rget_obj_type2(C.hid_t(at.Id()),
+		C.H5R_type_t(k), ref, &T)),
+		"getting type of referenced object")
+	return h5o.Type(T), err
+}
+
+// Gets the path of the referenced object
+// Wraps the H5Rget_name function
+func name(at core.Object, k kind, ref unsafe.Pointer) (core.Path, error) {
+	core.Lock()
+	defer core.Unlock()
+	sze := C.H5Rget_name(C.hid_t(at.Id()), C.H5R_type_t(k), ref, nil, 0)
+	if err := core.Status(int(sze),
+		"getting name of referenced object"); err != nil {
+		return "", err
+	}
+	out := make([]C.char, int(sze)+1)
+	if err := core.Status(int(C.H5Rget_name(C.hid_t(at.Id()),
+		C.H5R_type_t(k), ref, &out[0], C.size_t(len(out)))),
+		"getting name of referenced object"); err != nil {
+		return "", err
+	}
+	return core.Path(C.GoString(&out[0])), nil
+}
+
+// Opens the referenced object
+// Wraps the H5Rdereference2 function
+func open(at core.Object, k kind, ref unsafe.Pointer) (core.Id, error) {
+	core.Lock()
+	defer core.Unlock()
+	id := core.Id(C.H5Rdereference2(C.hid_t(at.Id()), C.H5P_DEFAULT,
+		C.H5R_type_t(k), ref))
+	core.Track(core.Id(id))
+	return id, core.Status(int(id), "dereferencing reference")
+}
+
+// Opens the referenced object, checking that it has the expected type
+func expect(at core.Object, k kind, ref unsafe.Pointer, T h5o.Type) (core.Id, error) {
+	actual, err := target(at, k, ref)
+	if err != nil {
+		return -1, err
+	}
+	if actual != T {
+		return -1, fmt.Errorf("Expecting a reference to a %s, got a %s", T, actual)
+	}
+	return open(at, k, ref)
+}
+
+// The type of the referenced object. The `at` object is any object
+// in the file containing the reference
+func (r Object) Target(at core.Object) (h5o.Type, error) {
+	return target(at, object, unsafe.Pointer(&r))
+}
+
+// The path of the referenced object in its file
+func (r Object) Name(at core.Object) (core.Path, error) {
+	return name(at, object, unsafe.Pointer(&r))
+}
+
+// Opens the referenced object, whatever its type. The Id returned
+// should be closed according to its type (see Target)
+func (r Object) Open(at core.Object) (core.Id, error) {
+	return open(at, object, unsafe.Pointer(&r))
+}
+
+// Opens the referenced group
+func (r Object) Group(at core.Object) (h5g.Group, error) {
+	id, err := expect(at, object, unsafe.Pointer(&r), h5o.GROUP)
+	return h5g.Group(id), err
+}
+
+// Opens the referenced dataset
+func (r Object) Dataset(at core.Object) (h5d.Dataset, error) {
+	id, err := expect(at, object, unsafe.Pointer(&r), h5o.DATASET)
+	return h5d.Dataset(id), err
+}
+
+// Opens the referenced named datatype
+func (r Object) Datatype(at core.Object) (h5t.Datatype, error) {
+	id, err := expect(at, object, unsafe.Pointer(&r), h5o.DATATYPE)
+	return h5t.Datatype(id), err
+}
+
+// The path of the dataset holding the region
+func (r Region) Name(at core.Object) (core.Path, error) {
+	return name(at, region, unsafe.Pointer(&r))
+}
+
+// Opens the dataset holding the region
+func (r Region) Dataset(at core.Object) (h5d.Dataset, error) {
+	id, err := open(at, region, unsafe.Pointer(&r))
+	return h5d.Dataset(id), err
+}
+
+// Gets a copy of the dataspace of the dataset, where the region is
+// selected. The `at` object is any object in the file containing
+// the reference. The dataspace should be closed after use
+// Wraps the H5Rget_region function
+func (r Region) Selection(at core.Object) (h5s.Dataspace, error) {
+	core.Lock()
+	defer core.Unlock()
+	id := h5s.Dataspace(C.H5Rget_region(C.hid_t(at.Id()),
+		C.H5R_DATASET_REGION, unsafe.Pointer(&r)))
+	core.Track(core.Id(id))
+	return id, core.Status(int(id), "getting referenced region")
+}
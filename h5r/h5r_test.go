@@ -0,0 +1,101 @@
+package h5r_test
+
+import (
+	"os"
+	"testing"
+)
+import (
+	"github.com/valoox/h5go"
+	"github.com/valoox/h5go/h5a"
+	"github.com/valoox/h5go/h5o"
+	"github.com/valoox/h5go/h5r"
+	"github.com/valoox/h5go/h5s"
+	"github.com/valoox/h5go/h5t"
+)
+
+// Stores references in an attribute and dereferences them
+func TestReferences(t *testing.T) {
+	const testfile = "./refs.h5"
+	f, err := h5go.Create(testfile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(testfile)
+	defer f.Close()
+	ds, err := f.NewDataset("data", make([]float64, 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ds.Close()
+	obj, err := h5r.NewObject(f, "data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	space, err := ds.Shape()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer space.Close()
+	sel := h5s.Hyperslab(space)
+	if err := sel.Set([]uint{10}, nil, []uint{5}, nil); err != nil {
+		t.Fatal(err)
+	}
+	reg, err := h5r.NewRegion(f, "data", sel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetAttr("objects", []h5r.Object{obj}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetAttr("region", reg); err != nil {
+		t.Fatal(err)
+	}
+	var objs []h5r.Object
+	if err := f.Attr("objects", &objs); err != nil {
+		t.Fatal(err)
+	}
+	if T, err := objs[0].Target(f); err != nil {
+		t.Fatal(err)
+	} else if T != h5o.DATASET {
+		t.Fatalf("Expecting a dataset, got a %s", T)
+	}
+	did, err := objs[0].Dataset(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer did.Close()
+	attr, err := h5a.Open(f, "region")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer attr.Close()
+	T, err := attr.Type()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer T.Close()
+	if cls, err := T.GetClass(); err != nil {
+		t.Fatal(err)
+	} else if cls != h5t.REF {
+		t.Fatalf("Expecting a reference attribute, got %s", cls.Name())
+	}
+	var back h5r.Region
+	if err := f.Attr("region", &back); err != nil {
+		t.Fatal(err)
+	}
+	if name, err := back.Name(f); err != nil {
+		t.Fatal(err)
+	} else if name != "/data" {
+		t.Fatalf("Expecting /data, got %s", name)
+	}
+	selected, err := back.Selection(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer selected.Close()
+	if n, err := selected.GetSelNPoints(); err != nil {
+		t.Fatal(err)
+	} else if n != 5 {
+		t.Fatalf("Expecting 5 selected points, got %v", n)
+	}
+}
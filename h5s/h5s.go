@@ -38,6 +38,16 @@ const (
 	ALL Dataspace = C.H5S_ALL
 )
 
+// The count (or block size) of a hyperslab extending without limit
+// along an unlimited dimension, as used by the mappings of virtual
+// datasets. This is the largest uint, which stands for the largest
+// hsize_t (H5S_UNLIMITED) in the coordinates given to or returned by
+// the library, whatever the size of uint on the platform.
+const UNLIMITED = ^uint(0)
+
+// The C value of UNLIMITED
+const unlimited C.hsize_t = C.H5S_UNLIMITED
+
 // The selection operators
 type OP int
 
@@ -73,28 +83,37 @@ const (
 
 // Returns the Id with a possible error if the id was negative
 func try(id Dataspace, context string, args ...interface{}) (Dataspace, error) {
+	core.Track(core.Id(id))
 	return id, core.Status(int(id), fmt.Sprintf(context, args...))
 }
 
 // Creates a new dataspace from a given class
 // Wraps H5Screate function
 func Create(cls Class) (Dataspace, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(Dataspace(C.H5Screate(cls.C())), "creating datatype")
 }
 
 // Copy the dataspace
 func Copy(id Dataspace) (Dataspace, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(Dataspace(C.H5Scopy(C.hid_t(id))), "copying dataspace")
 }
 
 // Disposes of the datatype. Wraps H5Sclose
 func Close(id Dataspace) error {
+	core.Lock()
+	defer core.Unlock()
 	return core.Status(int(C.H5Sclose(C.hid_t(id))),
 		"closing dataspace")
 }
 
 // Encodes the dataspace into a binary array
 func Encode(id Dataspace) ([]byte, error) {
+	core.Lock()
+	defer core.Unlock()
 	var sze C.size_t
 	if err := core.Status(int(C.H5Sencode(C.hid_t(id), nil,
 		&sze)), "computing encoding size"); err != nil {
@@ -109,6 +128,8 @@ func Encode(id Dataspace) ([]byte, error) {
 
 // Decodes the array of bytes into a dataspace
 func Decode(bin []byte) (Dataspace, error) {
+	core.Lock()
+	defer core.Unlock()
 	if len(bin) > 0 {
 		return try(Dataspace(C.H5Sdecode(unsafe.Pointer(&bin[0]))),
 			"decoding dataspace")
@@ -118,12 +139,16 @@ func Decode(bin []byte) (Dataspace, error) {
 
 // Creates a new Null dataspace
 func CreateNull() (Dataspace, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(Dataspace(C.H5Screate(NULL.C())),
 		"creating null dataspace")
 }
 
 // Creates a new scalar dataspace
 func CreateScalar() (Dataspace, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(Dataspace(C.H5Screate(SCALAR.C())),
 		"creating scalar dataspace")
 }
@@ -140,6 +165,8 @@ func CreateScalar() (Dataspace, error) {
 // If maxs is < 0, the value will be replaced by 'unlimited'
 // and the array can be expanded in this dimension without limit
 func CreateSimple(shape []int, maxs []int) (Dataspace, error) {
+	core.Lock()
+	defer core.Unlock()
 	n := len(shape)
 	current := make([]C.hsize_t, n)
 	for i, dim := range shape {
@@ -153,7 +180,7 @@ func CreateSimple(shape []int, maxs []int) (Dataspace, error) {
 		a := make([]C.hsize_t, len(maxs))
 		for i, m := range maxs {
 			if m < 0 {
-				a[i] = 0xffffffff & C.H5S_UNLIMITED
+				a[i] = C.H5S_UNLIMITED
 			} else {
 				a[i] = C.hsize_t(m)
 			}
@@ -164,6 +191,41 @@ func CreateSimple(shape []int, maxs []int) (Dataspace, error) {
 		"creating simple dataspace")
 }
 
+// Gets the current and maximum dimensions of the dataspace.
+// Scalar and null dataspaces have no dimension, and return empty
+// slices. Following the convention of CreateSimple, unlimited
+// maximum dimensions are reported as -1
+// Wraps the H5Sget_simple_extent_dims function
+func (ds Dataspace) GetDims() (dims []int, maxs []int, err error) {
+	core.Lock()
+	defer core.Unlock()
+	rank := C.H5Sget_simple_extent_ndims(C.hid_t(ds))
+	if err = core.Status(int(rank),
+		"getting dataspace rank"); err != nil {
+		return nil, nil, err
+	}
+	dims, maxs = make([]int, int(rank)), make([]int, int(rank))
+	if rank == 0 {
+		return
+	}
+	cdims := make([]C.hsize_t, int(rank))
+	cmaxs := make([]C.hsize_t, int(rank))
+	if err = core.Status(int(C.H5Sget_simple_extent_dims(C.hid_t(ds),
+		&cdims[0], &cmaxs[0])),
+		"getting dataspace dimensions"); err != nil {
+		return nil, nil, err
+	}
+	for i := range cdims {
+		dims[i] = int(cdims[i])
+		if cmaxs[i] == C.H5S_UNLIMITED {
+			maxs[i] = -1
+		} else {
+			maxs[i] = int(cmaxs[i])
+		}
+	}
+	return
+}
+
 // The C coordinates for the pointer
 func ccoords(args []uint) *C.hsize_t {
 	if args == nil || len(args) == 0 {
@@ -171,7 +233,11 @@ func ccoords(args []uint) *C.hsize_t {
 	}
 	cargs := make([]C.hsize_t, len(args))
 	for i, arg := range args {
-		cargs[i] = C.hsize_t(arg)
+		if arg == UNLIMITED {
+			cargs[i] = unlimited
+		} else {
+			cargs[i] = C.hsize_t(arg)
+		}
 	}
 	return &cargs[0]
 }
@@ -188,9 +254,14 @@ type Hyperslab Dataspace
 // The id of the hyperslab
 func (h Hyperslab) Dataspace() Dataspace { return Dataspace(h) }
 
+// The hyperslab implements the Selection interface
+func (h Hyperslab) Selection() Dataspace { return Dataspace(h) }
+
 // Gets the selection provided
 // Wraps the H5Sselect_hyperslab function
 func (h Hyperslab) Ref(selector OP, start, stride, count, block []uint) error {
+	core.Lock()
+	defer core.Unlock()
 	return core.Status(int(C.H5Sselect_hyperslab(C.hid_t(h),
 		C.H5S_seloper_t(selector),
 		ccoords(start),
@@ -235,8 +306,13 @@ type Points Dataspace
 // The Dataspace of the points
 func (pt Points) Dataspace() Dataspace { return Dataspace(pt) }
 
+// The points implement the Selection interface
+func (pt Points) Selection() Dataspace { return Dataspace(pt) }
+
 // Wraps the H5Sselect_elements function
 func (pt Points) Ref(op OP, coords [][]uint) error {
+	core.Lock()
+	defer core.Unlock()
 	if len(coords) == 0 {
 		return nil
 	}
@@ -270,3 +346,181 @@ func (pt Points) Append(coords ...[]uint) error {
 func (pt Points) Prepend(coords ...[]uint) error {
 	return pt.Ref(PREPEND, coords)
 }
+
+// Gets the rank (number of dimensions) of the dataspace
+// Wraps the H5Sget_simple_extent_ndims function
+func (ds Dataspace) GetRank() (int, error) {
+	core.Lock()
+	defer core.Unlock()
+	rank := int(C.H5Sget_simple_extent_ndims(C.hid_t(ds)))
+	return rank, core.Status(rank, "getting dataspace rank")
+}
+
+// Gets the number of elements in the dataspace
+// Wraps the H5Sget_simple_extent_npoints function
+func (ds Dataspace) GetNPoints() (int, error) {
+	core.Lock()
+	defer core.Unlock()
+	n := int(C.H5Sget_simple_extent_npoints(C.hid_t(ds)))
+	return n, core.Status(n, "getting dataspace size")
+}
+
+// Gets the class of the dataspace (scalar, simple or null)
+// Wraps the H5Sget_simple_extent_type function
+func (ds Dataspace) GetClass() (Class, error) {
+	core.Lock()
+	defer core.Unlock()
+	cls := Class(C.H5Sget_simple_extent_type(C.hid_t(ds)))
+	return cls, core.Status(int(cls), "getting dataspace class")
+}
+
+// Checks whether the dataspace is simple
+// Wraps the H5Sis_simple function
+func (ds Dataspace) IsSimple() (bool, error) {
+	core.Lock()
+	defer core.Unlock()
+	ok := int(C.H5Sis_simple(C.hid_t(ds)))
+	return ok > 0, core.Status(ok, "checking simple dataspace")
+}
+
+// Checks whether both dataspaces have the same extent (class, current
+// and maximum dimensions). The selections are not compared.
+// Wraps the H5Sextent_equal function
+func (ds Dataspace) Equal(other Dataspace) (bool, error) {
+	core.Lock()
+	defer core.Unlock()
+	ok := int(C.H5Sextent_equal(C.hid_t(ds), C.hid_t(other)))
+	return ok > 0, core.Status(ok, "comparing dataspaces")
+}
+
+// The types of selection in a dataspace
+type SelType int
+
+const (
+	SEL_NONE       SelType = C.H5S_SEL_NONE       // Nothing selected
+	SEL_POINTS     SelType = C.H5S_SEL_POINTS     // A set of points
+	SEL_HYPERSLABS SelType = C.H5S_SEL_HYPERSLABS // A set of hyperslabs
+	SEL_ALL        SelType = C.H5S_SEL_ALL        // The entire dataspace
+)
+
+// Gets the type of the current selection of the dataspace
+// Wraps the H5Sget_select_type function
+func (ds Dataspace) GetSelType() (SelType, error) {
+	core.Lock()
+	defer core.Unlock()
+	sel := SelType(C.H5Sget_select_type(C.hid_t(ds)))
+	return sel, core.Status(int(sel), "getting selection type")
+}
+
+// Gets the number of elements in the current selection
+// Wraps the H5Sget_select_npoints function
+func (ds Dataspace) GetSelNPoints() (int, error) {
+	core.Lock()
+	defer core.Unlock()
+	n := int(C.H5Sget_select_npoints(C.hid_t(ds)))
+	return n, core.Status(n, "getting selection size")
+}
+
+// Gets the bounding box of the current selection, as the coordinates
+// of its first and last (included) elements
+// Wraps the H5Sget_select_bounds function
+func (ds Dataspace) GetSelBounds() (start, end []uint, err error) {
+	core.Lock()
+	defer core.Unlock()
+	rank, err := ds.GetRank()
+	if err != nil || rank == 0 {
+		return nil, nil, err
+	}
+	cstart := make([]C.hsize_t, rank)
+	cend := make([]C.hsize_t, rank)
+	if err = core.Status(int(C.H5Sget_select_bounds(C.hid_t(ds),
+		&cstart[0], &cend[0])),
+		"getting selection bounds"); err != nil {
+		return nil, nil, err
+	}
+	return gocoords(cstart), gocoords(cend), nil
+}
+
+// The Go coordinates from the C ones
+func gocoords(cargs []C.hsize_t) []uint {
+	args := make([]uint, len(cargs))
+	for i, carg := range cargs {
+		if carg == unlimited {
+			args[i] = UNLIMITED
+		} else {
+			args[i] = uint(carg)
+		}
+	}
+	return args
+}
+
+// A block of a hyperslab selection, given by the coordinates of its
+// first and last (included) elements
+type Block struct {
+	Start, End []uint
+}
+
+// Gets the list of the blocks in the hyperslab selection
+// Wraps the H5Sget_select_hyper_nblocks and
+// H5Sget_select_hyper_blocklist functions
+func (h Hyperslab) GetBlocks() ([]Block, error) {
+	core.Lock()
+	defer core.Unlock()
+	rank, err := Dataspace(h).GetRank()
+	if err != nil {
+		return nil, err
+	}
+	n := int(C.H5Sget_select_hyper_nblocks(C.hid_t(h)))
+	if err := core.Status(n, "counting hyperslab blocks"); err != nil {
+		return nil, err
+	}
+	if n == 0 || rank == 0 {
+		return []Block{}, nil
+	}
+	buf := make([]C.hsize_t, 2*rank*n)
+	if err := core.Status(int(C.H5Sget_select_hyper_blocklist(C.hid_t(h),
+		0, C.hsize_t(n), &buf[0])),
+		"getting hyperslab blocks"); err != nil {
+		return nil, err
+	}
+	blocks := make([]Block, n)
+	for i := range blocks {
+		blk := buf[2*rank*i:]
+		blocks[i] = Block{
+			Start: gocoords(blk[:rank]),
+			End:   gocoords(blk[rank : 2*rank]),
+		}
+	}
+	return blocks, nil
+}
+
+// Gets the coordinates of the selected points, in the format used
+// by Set
+// Wraps the H5Sget_select_elem_npoints and
+// H5Sget_select_elem_pointlist functions
+func (pt Points) Get() ([][]uint, error) {
+	core.Lock()
+	defer core.Unlock()
+	rank, err := Dataspace(pt).GetRank()
+	if err != nil {
+		return nil, err
+	}
+	n := int(C.H5Sget_select_elem_npoints(C.hid_t(pt)))
+	if err := core.Status(n, "counting points"); err != nil {
+		return nil, err
+	}
+	if n == 0 || rank == 0 {
+		return [][]uint{}, nil
+	}
+	buf := make([]C.hsize_t, rank*n)
+	if err := core.Status(int(C.H5Sget_select_elem_pointlist(C.hid_t(pt),
+		0, C.hsize_t(n), &buf[0])),
+		"getting points"); err != nil {
+		return nil, err
+	}
+	coords := make([][]uint, n)
+	for i := range coords {
+		coords[i] = gocoords(buf[rank*i : rank*(i+1)])
+	}
+	return coords, nil
+}
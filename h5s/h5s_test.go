@@ -0,0 +1,136 @@
+package h5s
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// Inspects the extent of a simple dataspace
+func TestExtent(t *testing.T) {
+	ds, err := CreateSimple([]int{4, 6}, []int{-1, 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ds.Close()
+	if rank, err := ds.GetRank(); err != nil || rank != 2 {
+		t.Fatalf("Wrong rank: %v (%v)", rank, err)
+	}
+	if n, err := ds.GetNPoints(); err != nil || n != 24 {
+		t.Fatalf("Wrong size: %v (%v)", n, err)
+	}
+	if cls, err := ds.GetClass(); err != nil || cls != SIMPLE {
+		t.Fatalf("Wrong class: %v (%v)", cls, err)
+	}
+	dims, maxs, err := ds.GetDims()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dims, []int{4, 6}) ||
+		!reflect.DeepEqual(maxs, []int{-1, 6}) {
+		t.Fatalf("Wrong dimensions: %v, %v", dims, maxs)
+	}
+	cpy, err := ds.Copy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cpy.Close()
+	if ok, err := ds.Equal(cpy); err != nil || !ok {
+		t.Fatalf("Copy should be equal (%v)", err)
+	}
+	scalar, err := CreateScalar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer scalar.Close()
+	if ok, err := ds.Equal(scalar); err != nil || ok {
+		t.Fatalf("Scalar should differ (%v)", err)
+	}
+}
+
+// Inspects hyperslab and point selections
+func TestSelections(t *testing.T) {
+	ds, err := CreateSimple([]int{10, 10}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ds.Close()
+	if sel, err := ds.GetSelType(); err != nil || sel != SEL_ALL {
+		t.Fatalf("Wrong selection: %v (%v)", sel, err)
+	}
+	h := Hyperslab(ds)
+	if err := h.Set([]uint{1, 2}, []uint{4, 1}, []uint{2, 1},
+		[]uint{2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := ds.GetSelNPoints(); err != nil || n != 12 {
+		t.Fatalf("Wrong number of points: %v (%v)", n, err)
+	}
+	start, end, err := ds.GetSelBounds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(start, []uint{1, 2}) ||
+		!reflect.DeepEqual(end, []uint{6, 4}) {
+		t.Fatalf("Wrong bounds: %v-%v", start, end)
+	}
+	blocks, err := h.GetBlocks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Block{
+		{Start: []uint{1, 2}, End: []uint{2, 4}},
+		{Start: []uint{5, 2}, End: []uint{6, 4}},
+	}
+	if !reflect.DeepEqual(blocks, expected) {
+		t.Fatalf("Wrong blocks: %v", blocks)
+	}
+	pts := Points(ds)
+	if err := pts.Set([]uint{0, 1}, []uint{9, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if sel, err := ds.GetSelType(); err != nil || sel != SEL_POINTS {
+		t.Fatalf("Wrong selection: %v (%v)", sel, err)
+	}
+	coords, err := pts.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(coords, [][]uint{{0, 1}, {9, 3}}) {
+		t.Fatalf("Wrong points: %v", coords)
+	}
+}
+
+// Creates and inspects dataspaces from several goroutines
+func TestConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				ds, err := CreateSimple([]int{g + 1, i + 1}, nil)
+				if err != nil {
+					errs <- err
+					return
+				}
+				n, err := ds.GetNPoints()
+				ds.Close()
+				if err != nil {
+					errs <- err
+					return
+				} else if n != (g+1)*(i+1) {
+					errs <- fmt.Errorf("Wrong size: %v", n)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -4,6 +4,7 @@ package h5t
 
 /*
 #cgo LDFLAGS: -lhdf5
+#include <stdlib.h>
 #include <hdf5.h>
 #include "types.h"
 */
@@ -119,6 +120,15 @@ const (
 	MixedVAX     Order = C.H5T_ORDER_VAX // Mixed endianness
 )
 
+// How fixed-length strings are terminated or padded
+type Pad int
+
+const (
+	NullTerm Pad = C.H5T_STR_NULLTERM // Null-terminated
+	NullPad  Pad = C.H5T_STR_NULLPAD  // Padded with nulls
+	SpacePad Pad = C.H5T_STR_SPACEPAD // Padded with spaces (Fortran)
+)
+
 // The total number of classes
 const ttl = C.H5T_NCLASSES
 
@@ -140,6 +150,7 @@ var classnames = [ttl]string{
 // Processes a Datatype, returning an error if it is negative or nil
 // if it is ok
 func try(id Datatype, context string, args ...interface{}) (Datatype, error) {
+	core.Track(core.Id(id))
 	return id, core.Status(int(id), fmt.Sprintf(context, args...))
 }
 
@@ -152,6 +163,8 @@ func (t Datatype) Id() core.Id { return core.Id(t) }
 // Closes the datatype
 // Wraps H5Tclose
 func (t Datatype) Close() error {
+	core.Lock()
+	defer core.Unlock()
 	return core.Status(int(C.H5Tclose(C.hid_t(t))),
 		"closing datatype")
 }
@@ -159,6 +172,8 @@ func (t Datatype) Close() error {
 // Makes a copy of the datatype
 // Wraps H5Tcopy
 func (t Datatype) Copy() (Datatype, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(Datatype(C.H5Tcopy(C.hid_t(t))),
 		"copying datatype")
 }
@@ -166,12 +181,16 @@ func (t Datatype) Copy() (Datatype, error) {
 // Changes the encoding size of the type, setting it to `bytes` bytes
 // Wraps the H5Tset_size function
 func (t Datatype) SetSize(bytes int) error {
+	core.Lock()
+	defer core.Unlock()
 	return core.Status(int(C.H5Tset_size(C.hid_t(t),
 		C.size_t(bytes))), "setting bytes size")
 }
 
 // Sets the endianness of the datatype
 func (t Datatype) SetEndian(endian Order) error {
+	core.Lock()
+	defer core.Unlock()
 	return core.Status(int(C.H5Tset_order(C.hid_t(t),
 		C.H5T_order_t(endian))), "setting endianness")
 }
@@ -179,6 +198,8 @@ func (t Datatype) SetEndian(endian Order) error {
 // Sets the signedness of a numerical value
 // Wraps H5Tset_sign function
 func (t Datatype) SetSign(signed bool) error {
+	core.Lock()
+	defer core.Unlock()
 	var s int
 	if signed {
 		s = SIGNED
@@ -189,9 +210,60 @@ func (t Datatype) SetSign(signed bool) error {
 		C.H5T_sign_t(s))), "setting signedness")
 }
 
+// Gets the signedness of an integer type
+// Wraps the H5Tget_sign function
+func (t Datatype) GetSign() (signed bool, err error) {
+	core.Lock()
+	defer core.Unlock()
+	s := int(C.H5Tget_sign(C.hid_t(t)))
+	return s == SIGNED, core.Status(s, "getting signedness")
+}
+
+// Gets the class of the datatype
+// Wraps the H5Tget_class function
+func (t Datatype) GetClass() (Class, error) {
+	core.Lock()
+	defer core.Unlock()
+	cls := C.H5Tget_class(C.hid_t(t))
+	return Class(cls), core.Status(int(cls), "getting datatype class")
+}
+
+// Gets the size of the datatype, in bytes
+// Wraps the H5Tget_size function
+func (t Datatype) GetSize() (int, error) {
+	core.Lock()
+	defer core.Unlock()
+	sze := C.H5Tget_size(C.hid_t(t))
+	if sze == 0 {
+		return 0, core.Status(-1, "getting datatype size")
+	}
+	return int(sze), nil
+}
+
+// Checks whether the datatype is a variable-length string
+// Wraps the H5Tis_variable_str function
+func (t Datatype) IsVarString() (bool, error) {
+	core.Lock()
+	defer core.Unlock()
+	res := C.H5Tis_variable_str(C.hid_t(t))
+	return res > 0, core.Status(int(res),
+		"checking for variable-length string")
+}
+
+// Gets how a fixed-length string type is terminated or padded
+// Wraps the H5Tget_strpad function
+func (t Datatype) GetStrPad() (Pad, error) {
+	core.Lock()
+	defer core.Unlock()
+	pad := C.H5Tget_strpad(C.hid_t(t))
+	return Pad(pad), core.Status(int(pad), "getting string padding")
+}
+
 // Encodes the value into a binary array
 // Wraps the H5Tencode function
 func (t Datatype) Encode() ([]byte, error) {
+	core.Lock()
+	defer core.Unlock()
 	var sze C.size_t
 	if err := core.Status(int(C.H5Tencode(
 		C.hid_t(t), nil, &sze)),
@@ -208,6 +280,8 @@ func (t Datatype) Encode() ([]byte, error) {
 // Commits the type to the location
 func (t Datatype) Commit(in core.Location, name string,
 	links h5l.Crt, create Crt, access Acc) error {
+	core.Lock()
+	defer core.Unlock()
 	return core.Status(int(C.H5Tcommit2(C.hid_t(in.At()),
 		C.CString(name),
 		C.hid_t(t),
@@ -220,12 +294,16 @@ func (t Datatype) Commit(in core.Location, name string,
 // Wraps te H5Tcreate function. Additional modifications can be done
 // using the other functions in this package (SetSize, SetSign...)
 func Create(base Class, bytes int) (Datatype, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(Datatype(C.H5Tcreate(base.C(), C.size_t(bytes))),
 		"creating datatype")
 }
 
 // Opens a saved datatype from the location
 func Open(from core.Location, name string, access Acc) (Datatype, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(Datatype(C.H5Topen(
 		C.hid_t(from.At()),
 		C.CString(name),
@@ -235,12 +313,16 @@ func Open(from core.Location, name string, access Acc) (Datatype, error) {
 
 // Checks for type equality, wrapping H5Tequal
 func Eq(T1, T2 Datatype) bool {
+	core.Lock()
+	defer core.Unlock()
 	return (C.H5Tequal(C.hid_t(T1), C.hid_t(T2)) > 0)
 }
 
 // Decodes a binary representation of a type
 // Wraps the H5Tdecode function
 func Decode(bin []byte) (Datatype, error) {
+	core.Lock()
+	defer core.Unlock()
 	if len(bin) > 0 {
 		try(Datatype(C.H5Tdecode(unsafe.Pointer(&bin[0]))),
 			"decoding encoded datatype")
@@ -436,6 +518,8 @@ func RawBin(length int) (Datatype, error) {
 // The utf8 flag states whether the encoding should be UTF-8
 // (true) or ASCII (false)
 func String(length int, utf8 bool) (T Datatype, err error) {
+	core.Lock()
+	defer core.Unlock()
 	if length < 0 {
 		T, err = varstring()
 	} else {
@@ -469,12 +553,16 @@ func varstring() (T Datatype, err error) {
 
 // Creates a variable-length array of object of this type
 func List(T Datatype) (Datatype, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(Datatype(C.H5Tvlen_create(C.hid_t(T))),
 		"creating varlength array datatype")
 }
 
 // Creates a N-dimensional array with the provided dimensions
 func NDarray(T Datatype, dims ...uint) (Datatype, error) {
+	core.Lock()
+	defer core.Unlock()
 	rank := len(dims)
 	cdims := make([]C.hsize_t, rank)
 	for i, d := range dims {
@@ -497,6 +585,8 @@ type Field struct {
 
 // Creates a compound structure from the provided set of fields
 func Struct(fullsize int, fields ...Field) (T Datatype, err error) {
+	core.Lock()
+	defer core.Unlock()
 	T, err = Create(COMPOUND, fullsize)
 	if err != nil {
 		return
@@ -515,6 +605,76 @@ func Struct(fullsize int, fields ...Field) (T Datatype, err error) {
 	return
 }
 
+// Gets the number of fields of a compound datatype (or of members of
+// an enumeration)
+// Wraps the H5Tget_nmembers function
+func (t Datatype) GetNMembers() (int, error) {
+	core.Lock()
+	defer core.Unlock()
+	n := int(C.H5Tget_nmembers(C.hid_t(t)))
+	return n, core.Status(n, "getting number of members")
+}
+
+// Gets the name of the i-th field of a compound datatype
+// Wraps the H5Tget_member_name function
+func (t Datatype) GetMemberName(i int) (string, error) {
+	core.Lock()
+	defer core.Unlock()
+	name := C.H5Tget_member_name(C.hid_t(t), C.unsigned(i))
+	if name == nil {
+		return "", core.Status(-1, "getting name of member %v", i)
+	}
+	defer C.H5free_memory(unsafe.Pointer(name))
+	return C.GoString(name), nil
+}
+
+// Gets the index of the field with the given name
+// Wraps the H5Tget_member_index function
+func (t Datatype) GetMemberIndex(name string) (int, error) {
+	core.Lock()
+	defer core.Unlock()
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	i := int(C.H5Tget_member_index(C.hid_t(t), cname))
+	return i, core.Status(i, "getting index of member %s", name)
+}
+
+// Gets the type of the i-th field of a compound datatype, which
+// should be closed after use
+// Wraps the H5Tget_member_type function
+func (t Datatype) GetMemberType(i int) (Datatype, error) {
+	core.Lock()
+	defer core.Unlock()
+	return try(Datatype(C.H5Tget_member_type(C.hid_t(t),
+		C.unsigned(i))), "getting type of member %v", i)
+}
+
+// Gets the fields of a compound datatype. The types of the fields
+// should be closed after use
+func (t Datatype) GetFields() ([]Field, error) {
+	core.Lock()
+	defer core.Unlock()
+	n, err := t.GetNMembers()
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]Field, n)
+	for i := range fields {
+		if fields[i].Name, err = t.GetMemberName(i); err != nil {
+			return nil, err
+		}
+		fields[i].Offset = int(C.H5Tget_member_offset(C.hid_t(t),
+			C.unsigned(i)))
+		if fields[i].Type, err = t.GetMemberType(i); err != nil {
+			for _, fld := range fields[:i] {
+				fld.Type.Close()
+			}
+			return nil, err
+		}
+	}
+	return fields, nil
+}
+
 // The interface shared by the different enumerated types
 type Enum interface {
 	core.Object
@@ -538,6 +698,8 @@ type enum64 enum
 
 // Creates a new enumeration from the given native type
 func mkenum(T C.hid_t) (Datatype, error) {
+	core.Lock()
+	defer core.Unlock()
 	return try(Datatype(C.H5Tenum_create(T)), "creating enum")
 }
 
@@ -565,6 +727,8 @@ func NewEnum(size uint8) (Enum, error) {
 
 // Sets a value in an enum
 func enumset(e Enum, name string, ptr unsafe.Pointer) error {
+	core.Lock()
+	defer core.Unlock()
 	return core.Status(int(C.H5Tenum_insert(
 		C.hid_t(e.Id()),
 		C.CString(name),
@@ -573,6 +737,8 @@ func enumset(e Enum, name string, ptr unsafe.Pointer) error {
 
 // Gets the value of the enum
 func enumvalue(e Enum, name string, ptr unsafe.Pointer) error {
+	core.Lock()
+	defer core.Unlock()
 	return core.Status(int(C.H5Tenum_valueof(
 		C.hid_t(e.Id()),
 		C.CString(name),
@@ -581,6 +747,8 @@ func enumvalue(e Enum, name string, ptr unsafe.Pointer) error {
 
 // Gets the name associated with the value
 func enumname(e Enum, ptr unsafe.Pointer) (string, error) {
+	core.Lock()
+	defer core.Unlock()
 	out := make([]C.char, 8)
 	err := core.Status(int(C.H5Tenum_nameof(
 		C.hid_t(e.Id()),
@@ -117,8 +117,22 @@ func structure(v reflect.Type, lc core.Location) (Datatype, error) {
 	return Struct(int(v.Size()), fields...)
 }
 
+// Go types implementing this interface provide their own datatype,
+// which is used by Parse instead of the one inferred by reflection
+// (e.g. the references of the h5r package)
+type Typed interface {
+	// Produces the datatype of the values
+	Type() (Datatype, error)
+}
+
+// The reflected Typed interface
+var typed = reflect.TypeOf((*Typed)(nil)).Elem()
+
 // Parses the reflected value and returns the correpsonding datatype
 func parse(T reflect.Type, ctxt core.Location) (Datatype, error) {
+	if T.Implements(typed) {
+		return reflect.Zero(T).Interface().(Typed).Type()
+	}
 	switch K := T.Kind(); K {
 	case reflect.Array:
 		// A fixed-length array
@@ -167,3 +181,13 @@ func Parse(obj interface{}, ctxt core.Location) (Datatype, error) {
 	val := reflect.ValueOf(obj)
 	return parse(val.Type(), ctxt)
 }
+
+// Same as Parse, but works directly on the reflected type. This is
+// useful when no value of the type is at hand (e.g. when allocating
+// the elements of a slice to be read)
+func ParseType(T reflect.Type, ctxt core.Location) (Datatype, error) {
+	if T == nil {
+		return -1, fmt.Errorf("Nothing provided")
+	}
+	return parse(T, ctxt)
+}
@@ -0,0 +1,50 @@
+package h5t
+
+/*
+#cgo LDFLAGS: -lhdf5
+#include <stdlib.h>
+#include <hdf5.h>
+*/
+import "C"
+import "unsafe"
+import "github.com/valoox/h5go/core"
+
+// Go strings cannot be handed to the library directly, as variable
+// length strings are represented as arrays of C `char*`. These
+// helpers convert between both representations.
+
+// Allocates C copies of the strings, so that they can be written as
+// variable-length strings. The pointers returned are owned by the
+// caller, and must be released using FreeCStrings
+func CStrings(strs []string) []unsafe.Pointer {
+	out := make([]unsafe.Pointer, len(strs))
+	for i, s := range strs {
+		out[i] = unsafe.Pointer(C.CString(s))
+	}
+	return out
+}
+
+// Releases the C strings allocated by CStrings
+func FreeCStrings(ptrs []unsafe.Pointer) {
+	for _, p := range ptrs {
+		C.free(p)
+	}
+}
+
+// Converts the variable-length strings read from the library into
+// Go strings. The memory allocated by the library for the C strings
+// is released, so the pointers must not be used afterwards.
+// Wraps the H5free_memory function
+func GoStrings(ptrs []unsafe.Pointer) []string {
+	core.Lock()
+	defer core.Unlock()
+	out := make([]string, len(ptrs))
+	for i, p := range ptrs {
+		if p == nil {
+			continue
+		}
+		out[i] = C.GoString((*C.char)(p))
+		C.H5free_memory(p)
+	}
+	return out
+}
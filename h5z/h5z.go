@@ -0,0 +1,135 @@
+// This wraps the H5Z* family of functions, which manage the filters
+// (compression, checksums...) applied to the chunks of datasets.
+// The filters themselves are set on the dataset creation property
+// lists (see h5d.Crt).
+package h5z
+
+/*
+#cgo LDFLAGS: -lhdf5
+#include <hdf5.h>
+*/
+import "C"
+import "fmt"
+
+import "github.com/valoox/h5go/core"
+
+// Represents the identifier of a filter
+type Filter int
+
+// The predefined filters
+const (
+	DEFLATE     Filter = C.H5Z_FILTER_DEFLATE     // gzip compression
+	SHUFFLE     Filter = C.H5Z_FILTER_SHUFFLE     // Byte shuffling
+	FLETCHER32  Filter = C.H5Z_FILTER_FLETCHER32  // Checksum
+	SZIP        Filter = C.H5Z_FILTER_SZIP        // szip compression
+	NBIT        Filter = C.H5Z_FILTER_NBIT        // N-bit packing
+	SCALEOFFSET Filter = C.H5Z_FILTER_SCALEOFFSET // Scale-offset packing
+)
+
+// The name of the filter
+func (f Filter) String() string {
+	switch f {
+	case DEFLATE:
+		return "deflate"
+	case SHUFFLE:
+		return "shuffle"
+	case FLETCHER32:
+		return "fletcher32"
+	case SZIP:
+		return "szip"
+	case NBIT:
+		return "nbit"
+	case SCALEOFFSET:
+		return "scaleoffset"
+	}
+	return fmt.Sprintf("filter %d", int(f))
+}
+
+// The flags of a filter in a pipeline
+type Flag uint
+
+const (
+	// The filter must succeed, or the write fails
+	MANDATORY Flag = C.H5Z_FLAG_MANDATORY
+	// The filter is skipped when it fails
+	OPTIONAL Flag = C.H5Z_FLAG_OPTIONAL
+)
+
+// The type of scaling of the scale-offset filter
+type ScaleType int
+
+const (
+	// Floats, keeping the given number of decimal digits
+	FLOAT_DSCALE ScaleType = C.H5Z_SO_FLOAT_DSCALE
+	// Floats, using exponent scaling (not implemented by HDF5)
+	FLOAT_ESCALE ScaleType = C.H5Z_SO_FLOAT_ESCALE
+	// Integers, keeping the given number of bits
+	INT ScaleType = C.H5Z_SO_INT
+)
+
+// The options of the szip filter
+const (
+	SZIP_EC uint = C.H5_SZIP_EC_OPTION_MASK // Entropy coding
+	SZIP_NN uint = C.H5_SZIP_NN_OPTION_MASK // Nearest neighbour coding
+)
+
+// Describes a filter of a pipeline
+type Info struct {
+	// The identifier of the filter
+	Id Filter
+	// The flags of the filter
+	Flags Flag
+	// The auxiliary parameters of the filter
+	CdValues []uint
+	// The name of the filter
+	Name string
+	// Whether the filter can encode and decode data
+	Encode, Decode bool
+}
+
+// Checks whether the filter is available in the library
+// Wraps the H5Zfilter_avail function
+func Available(f Filter) (bool, error) {
+	core.Lock()
+	defer core.Unlock()
+	res := C.H5Zfilter_avail(C.H5Z_filter_t(f))
+	return res > 0, core.Status(int(res),
+		"checking availability of %s", f)
+}
+
+// Gets whether the filter can encode (compress) and decode
+// (decompress) data. Some filters (such as szip) can be built with
+// decoding only
+// Wraps the H5Zget_filter_info function
+func Config(f Filter) (encode, decode bool, err error) {
+	core.Lock()
+	defer core.Unlock()
+	var flags C.uint
+	if err = core.Status(int(C.H5Zget_filter_info(C.H5Z_filter_t(f),
+		&flags)), "getting information on %s", f); err != nil {
+		return
+	}
+	encode = flags&C.H5Z_FILTER_CONFIG_ENCODE_ENABLED != 0
+	decode = flags&C.H5Z_FILTER_CONFIG_DECODE_ENABLED != 0
+	return
+}
+
+// Returns an error if the filter is not available, or if it cannot
+// encode data (when `encode` is set), so that missing filters are
+// reported before any data is written
+func Require(f Filter, encode bool) error {
+	if ok, err := Available(f); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("Filter %s is not available", f)
+	}
+	if !encode {
+		return nil
+	}
+	if enc, _, err := Config(f); err != nil {
+		return err
+	} else if !enc {
+		return fmt.Errorf("Filter %s cannot encode data", f)
+	}
+	return nil
+}
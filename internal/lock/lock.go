@@ -0,0 +1,86 @@
+// Serialises the calls to the HDF5 library when it was not built
+// thread-safe. This lives apart from core (which re-exports it) so
+// that the h5e package, which core depends on, can also use it.
+package lock
+
+/*
+#cgo LDFLAGS: -lhdf5
+#include <hdf5.h>
+#include <pthread.h>
+#include <stdint.h>
+
+static uintptr_t self_thread() { return (uintptr_t)pthread_self(); }
+*/
+import "C"
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Whether the library is thread-safe, in which case it serialises
+// the calls itself and no lock is taken here
+var threadsafe bool
+
+func init() {
+	var ts C.hbool_t
+	threadsafe = C.H5is_library_threadsafe(&ts) >= 0 && bool(ts)
+}
+
+// Whether the library was built thread-safe
+func Threadsafe() bool { return threadsafe }
+
+// The global lock. It is reentrant for the thread holding it, as
+// the library calls back into Go (iterations, error handlers) on the
+// thread of the original call, and the callbacks can call the
+// library again
+var global struct {
+	sync.Mutex
+	owner uintptr // The thread holding the lock, or 0 (atomic)
+	depth int     // The number of times it holds the lock
+}
+
+// Called whenever a thread enters the library (see OnEnter)
+var enter func()
+
+// Sets the function called whenever a thread enters the library,
+// with the lock held. This lets the h5e package apply its settings,
+// which thread-safe builds keep per thread. It must be set before
+// the library is used concurrently.
+func OnEnter(fn func()) { enter = fn }
+
+// Takes the global lock, unless the library is thread-safe. Either
+// way, the goroutine stays on its current thread until Unlock is
+// called, as thread-safe builds keep the error stack of the calls
+// per thread.
+func Lock() {
+	runtime.LockOSThread()
+	if threadsafe {
+		if enter != nil {
+			enter()
+		}
+		return
+	}
+	self := uintptr(C.self_thread())
+	if atomic.LoadUintptr(&global.owner) == self {
+		global.depth++
+		return
+	}
+	global.Lock()
+	atomic.StoreUintptr(&global.owner, self)
+	global.depth = 1
+	if enter != nil {
+		enter()
+	}
+}
+
+// Releases the global lock taken by Lock
+func Unlock() {
+	if !threadsafe {
+		if global.depth--; global.depth == 0 {
+			atomic.StoreUintptr(&global.owner, 0)
+			global.Unlock()
+		}
+	}
+	runtime.UnlockOSThread()
+}
@@ -0,0 +1,63 @@
+package h5go
+
+import (
+	"os"
+	"testing"
+)
+import (
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5l"
+	"github.com/valoox/h5go/h5o"
+)
+
+// Lists and visits the content of a file
+func TestIterate(t *testing.T) {
+	const testfile = "./iterate.h5"
+	f, err := Create(testfile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(testfile)
+	defer f.Close()
+	for _, name := range []core.Path{"b", "a"} {
+		g, err := f.NewGroup(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer g.Close()
+	}
+	ds, err := f.NewDataset("a/data", []int32{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ds.Close()
+	keys, err := f.Keys()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Fatalf("Wrong keys: %v", keys)
+	}
+	var paths []string
+	if err := f.Visit(core.ByName, core.Increasing,
+		func(name string, info h5l.Info) error {
+			paths = append(paths, name)
+			return nil
+		}); err != nil {
+		t.Fatal(err)
+	} else if len(paths) != 3 || paths[1] != "a/data" {
+		t.Fatalf("Wrong paths: %v", paths)
+	}
+	found := ""
+	if err := f.VisitObjects(core.ByName, core.Increasing,
+		func(name string, info h5o.Info) error {
+			if info.Type == h5o.DATASET {
+				found = name
+				return core.Stop
+			}
+			return nil
+		}); err != nil {
+		t.Fatal(err)
+	} else if found != "a/data" {
+		t.Fatalf("Expected a/data, found %q", found)
+	}
+}
@@ -0,0 +1,66 @@
+// Detects the HDF5 handles leaked by tests.
+//
+// Typical usage, at the start of a test:
+//
+//	defer leaks.Check(t)()
+//
+// which fails the test if some files, groups, datasets, attributes,
+// dataspaces, datatypes or property lists opened during the test are
+// still open when it returns, reporting where each one was created.
+package leaks
+
+import (
+	"testing"
+)
+import (
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5f"
+)
+
+// Starts tracking the ids created, and returns the function checking
+// that they have all been closed, which should be deferred. Handles
+// already open when Check is called are ignored.
+func Check(t testing.TB) func() {
+	t.Helper()
+	was := core.Tracking()
+	before := make(map[core.Id]bool)
+	if was {
+		for _, h := range core.Handles() {
+			before[h.Id] = true
+		}
+	}
+	objs, err := h5f.OpenObjects()
+	if err != nil {
+		t.Fatalf("Listing open objects: %s", err)
+	}
+	for _, obj := range objs {
+		before[obj.Id] = true
+	}
+	if !was {
+		core.SetTracking(true)
+	}
+	return func() {
+		t.Helper()
+		reported := make(map[core.Id]bool)
+		for _, h := range core.Handles() {
+			if !before[h.Id] {
+				reported[h.Id] = true
+				t.Errorf("Leaked %s", h)
+			}
+		}
+		// Objects opened without going through h5go, which were not
+		// tracked but are still open in some file
+		objs, err := h5f.OpenObjects()
+		if err != nil {
+			t.Errorf("Listing open objects: %s", err)
+		}
+		for _, obj := range objs {
+			if !before[obj.Id] && !reported[obj.Id] {
+				t.Errorf("Leaked %s %d (untracked)", obj.Kind, obj.Id)
+			}
+		}
+		if !was {
+			core.SetTracking(false)
+		}
+	}
+}
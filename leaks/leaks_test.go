@@ -0,0 +1,63 @@
+package leaks
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+import (
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5s"
+)
+
+// Records the errors instead of failing the test
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// Reports the dataspaces left open, and only those
+func TestCheck(t *testing.T) {
+	rec := &recorder{TB: t}
+	done := Check(rec)
+	if !core.Tracking() {
+		t.Fatal("Tracking not enabled")
+	}
+	closed, err := h5s.CreateSimple([]int{3}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := closed.Close(); err != nil {
+		t.Fatal(err)
+	}
+	leaked, err := h5s.CreateSimple([]int{2, 2}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer leaked.Close()
+	done()
+	if core.Tracking() {
+		t.Error("Tracking still enabled")
+	}
+	if len(rec.errors) != 1 {
+		t.Fatalf("Expected a single leak, got %v", rec.errors)
+	}
+	if msg := rec.errors[0]; !strings.Contains(msg, "dataspace") ||
+		!strings.Contains(msg, "TestCheck") {
+		t.Errorf("Wrong report: %s", msg)
+	}
+}
+
+// Passes when everything is closed
+func TestClean(t *testing.T) {
+	defer Check(t)()
+	S, err := h5s.CreateScalar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	S.Close()
+}
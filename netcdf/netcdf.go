@@ -0,0 +1,200 @@
+// This implements the netCDF-4 data model on top of HDF5, following
+// the conventions of the netCDF-4 library, so that the files written
+// can be read by ncdump and the other netCDF tools, and that files
+// written by the netCDF library can be read back into Go.
+//
+// In netCDF-4 files, dimensions are dimension scales (see the h5ds
+// package), variables are datasets to which the scales of their
+// dimensions are attached, and attributes are HDF5 attributes of the
+// root group (global attributes) or of the variables. Only the root
+// group and the atomic netCDF types (integers, floating point values
+// and strings) are supported.
+package netcdf
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+import (
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5s"
+	"github.com/valoox/h5go/h5t"
+)
+
+// A dimension of the dataset, which can be unlimited (the netCDF
+// record dimension), in which case its length is its current length
+type Dim struct {
+	Name      string // The name of the dimension
+	Len       int    // The (current) length of the dimension
+	Unlimited bool   // Whether the dimension can grow
+}
+
+// An attribute, of the dataset or of a variable. The value is a
+// scalar or a slice of one of the netCDF types; Go strings are stored
+// as netCDF text (NC_CHAR) while slices of strings are stored as
+// netCDF strings (NC_STRING)
+type Attr struct {
+	Name  string      // The name of the attribute
+	Value interface{} // The value of the attribute
+}
+
+// A variable of the dataset, defined over some of its dimensions
+type Var struct {
+	Name string   // The name of the variable
+	Dims []string // The names of the dimensions of the variable
+	// The data of the variable, as (possibly nested) slices of the
+	// type of the variable, holding as many elements as given by its
+	// dimensions. When reading, data is returned as nested slices,
+	// one level per dimension
+	Data  interface{}
+	Fill  interface{} // The fill value (_FillValue), or nil
+	Attrs []Attr      // The attributes of the variable
+}
+
+// A netCDF dataset, i.e. the content of a netCDF-4 file
+type Dataset struct {
+	Dims  []Dim  // The dimensions, in order of their ids
+	Vars  []Var  // The variables
+	Attrs []Attr // The global attributes
+	// The _NCProperties attribute, describing the software which
+	// wrote the file. This is only read: Write sets its own.
+	Properties string
+}
+
+// The name of the dimension scales which are not variables
+const phony = "This is a netCDF dimension but not a netCDF variable."
+
+// The attributes used by the netCDF conventions, which are not
+// exposed as attributes of the model
+var hidden = map[string]bool{
+	"CLASS":               true,
+	"NAME":                true,
+	"REFERENCE_LIST":      true,
+	"DIMENSION_LIST":      true,
+	"DIMENSION_LABELS":    true,
+	"_Netcdf4Dimid":       true,
+	"_Netcdf4Coordinates": true,
+	"_NCProperties":       true,
+	"_nc3_strict":         true,
+	"_FillValue":          true,
+}
+
+// The element type of the (possibly nested) slices, checking that it
+// is an atomic netCDF type
+func elemType(data interface{}) (reflect.Type, error) {
+	if data == nil {
+		return nil, fmt.Errorf("Nothing provided")
+	}
+	T := reflect.TypeOf(data)
+	for T.Kind() == reflect.Slice || T.Kind() == reflect.Array {
+		T = T.Elem()
+	}
+	switch T.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return T, nil
+	}
+	return nil, fmt.Errorf("%s is not a netCDF type", T)
+}
+
+// The Go integer types, by size: unsigned first, then signed
+var integers = map[int][2]reflect.Type{
+	1: {reflect.TypeOf(uint8(0)), reflect.TypeOf(int8(0))},
+	2: {reflect.TypeOf(uint16(0)), reflect.TypeOf(int16(0))},
+	4: {reflect.TypeOf(uint32(0)), reflect.TypeOf(int32(0))},
+	8: {reflect.TypeOf(uint64(0)), reflect.TypeOf(int64(0))},
+}
+
+// The Go type corresponding to the netCDF type of the datatype
+func goType(T h5t.Datatype) (reflect.Type, error) {
+	cls, err := T.GetClass()
+	if err != nil {
+		return nil, err
+	}
+	size, err := T.GetSize()
+	if err != nil {
+		return nil, err
+	}
+	switch cls {
+	case h5t.INTEGER:
+		signed, err := T.GetSign()
+		if err != nil {
+			return nil, err
+		}
+		ints, ok := integers[size]
+		if !ok {
+			return nil, fmt.Errorf("Unsupported integer size: %v", size)
+		} else if signed {
+			return ints[1], nil
+		}
+		return ints[0], nil
+	case h5t.FLOAT:
+		switch size {
+		case 4:
+			return reflect.TypeOf(float32(0)), nil
+		case 8:
+			return reflect.TypeOf(float64(0)), nil
+		}
+		return nil, fmt.Errorf("Unsupported float size: %v", size)
+	case h5t.STRING:
+		return reflect.TypeOf(""), nil
+	}
+	return nil, fmt.Errorf("Unsupported netCDF type: %s", cls.Name())
+}
+
+// The version of the netCDF library whose conventions are followed,
+// as reported in the _NCProperties attribute
+const ncVersion = "4.9.2"
+
+// The value of the _NCProperties attribute, in the form written by
+// the netCDF library
+func properties() string {
+	major, minor, release, err := core.GetVersion()
+	if err != nil {
+		return "version=2,netcdf=" + ncVersion
+	}
+	return fmt.Sprintf("version=2,netcdf=%s,hdf5=%d.%d.%d",
+		ncVersion, major, minor, release)
+}
+
+// NetCDF text (NC_CHAR), stored as a fixed-length string of its exact
+// length, without the trailing null character: this is how the
+// netCDF library stores text attributes, which would otherwise be
+// read one character too long. Empty text has a null dataspace.
+type text []byte
+
+// The single character of the type of empty text
+var empty = []byte{0}
+
+// Implements the h5d.IBuffer interface
+func (s text) Type() (h5t.Datatype, error) {
+	if len(s) == 0 {
+		return h5t.String(1, false)
+	}
+	return h5t.String(len(s), false)
+}
+func (s text) Shape() (h5s.Dataspace, error) {
+	if len(s) == 0 {
+		return h5s.CreateNull()
+	}
+	return h5s.CreateScalar()
+}
+func (s text) ReadPtr() unsafe.Pointer {
+	if len(s) == 0 {
+		return unsafe.Pointer(&empty[0])
+	}
+	return unsafe.Pointer(&s[0])
+}
+
+// Sets the attribute, storing Go strings as netCDF text
+func setAttr(set func(string, interface{}) error, attr Attr) error {
+	if _, err := elemType(attr.Value); err != nil {
+		return fmt.Errorf("Attribute %s: %s", attr.Name, err)
+	}
+	if s, ok := attr.Value.(string); ok {
+		return set(attr.Name, text(s))
+	}
+	return set(attr.Name, attr.Value)
+}
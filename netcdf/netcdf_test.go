@@ -0,0 +1,124 @@
+package netcdf
+
+import (
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+import (
+	"github.com/valoox/h5go"
+	"github.com/valoox/h5go/h5a"
+)
+
+// The size of the type of a global attribute of the file
+func attrSize(path, name string) (int, error) {
+	f, err := h5go.Open(path, false)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	attr, err := h5a.Open(f, name)
+	if err != nil {
+		return 0, err
+	}
+	defer attr.Close()
+	T, err := attr.Type()
+	if err != nil {
+		return 0, err
+	}
+	defer T.Close()
+	return T.GetSize()
+}
+
+// Writes a dataset and reads it back
+func TestRoundTrip(t *testing.T) {
+	const testfile = "./roundtrip.nc"
+	defer os.Remove(testfile)
+	in := &Dataset{
+		Dims: []Dim{
+			{Name: "time", Len: 2, Unlimited: true},
+			{Name: "lat", Len: 3},
+			{Name: "station", Len: 2},
+		},
+		Vars: []Var{
+			{
+				Name: "lat",
+				Dims: []string{"lat"},
+				Data: []float64{-10, 0, 10},
+				Attrs: []Attr{
+					{"units", "degrees_north"},
+				},
+			},
+			{
+				Name: "temperature",
+				Dims: []string{"time", "lat"},
+				Data: [][]float32{{1, 2, 3}, {4, 5, 6}},
+				Fill: float32(-999),
+				Attrs: []Attr{
+					{"long_name", "Surface temperature"},
+					{"valid_range", []float32{-100, 100}},
+				},
+			},
+			{
+				Name: "ids",
+				Dims: []string{"station"},
+				Data: []int32{7, 11},
+			},
+		},
+		Attrs: []Attr{
+			{"comment", ""},
+			{"title", "Round trip"},
+			{"version", int32(3)},
+		},
+	}
+	if err := Write(testfile, in); err != nil {
+		t.Fatal(err)
+	}
+	out, err := Read(testfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.Properties, "version=2,netcdf=") {
+		t.Errorf("Wrong properties: %q", out.Properties)
+	}
+	if size, err := attrSize(testfile, "title"); err != nil {
+		t.Fatal(err)
+	} else if size != len("Round trip") {
+		t.Errorf("Expected text of %v characters, got %v", len("Round trip"), size)
+	}
+	if !reflect.DeepEqual(out.Dims, in.Dims) {
+		t.Errorf("Wrong dimensions: %v", out.Dims)
+	}
+	// Attributes are read in alphanumerical order
+	if !reflect.DeepEqual(out.Attrs, in.Attrs) {
+		t.Errorf("Wrong attributes: %v", out.Attrs)
+	}
+	// Variables are read in alphanumerical order
+	expected := []Var{in.Vars[2], in.Vars[0], in.Vars[1]}
+	if !reflect.DeepEqual(out.Vars, expected) {
+		t.Errorf("Wrong variables: %v", out.Vars)
+	}
+	if ncdump, err := exec.LookPath("ncdump"); err == nil {
+		if msg, err := exec.Command(ncdump, testfile).CombinedOutput(); err != nil {
+			t.Errorf("ncdump failed: %s\n%s", err, msg)
+		}
+	}
+}
+
+// Rejects invalid datasets
+func TestInvalid(t *testing.T) {
+	const testfile = "./invalid.nc"
+	defer os.Remove(testfile)
+	for _, ds := range []*Dataset{
+		{Vars: []Var{{Name: "x", Dims: []string{"missing"}, Data: []int32{1}}}},
+		{Vars: []Var{{Name: "flag", Data: true}}},
+		{Dims: []Dim{{Name: "x", Len: 2}},
+			Vars: []Var{{Name: "v", Dims: []string{"x"}, Data: []int16{1, 2}, Fill: 0}}},
+	} {
+		if err := Write(testfile, ds); err == nil {
+			t.Errorf("Expected an error writing %v", ds)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package netcdf
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+import (
+	"github.com/valoox/h5go"
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5a"
+	"github.com/valoox/h5go/h5o"
+	"github.com/valoox/h5go/h5s"
+)
+
+// Reads a netCDF-4 file into the dataset model. Variables and
+// attributes are listed in alphanumerical order, while dimensions
+// follow their netCDF ids. Axes of variables without any dimension
+// scale are given phony dimensions, as the netCDF library does.
+func Read(path string) (*Dataset, error) {
+	f, err := h5go.Open(path, false)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	out := new(Dataset)
+	if ok, err := f.HasAttr("_NCProperties"); err != nil {
+		return nil, err
+	} else if ok {
+		if err := f.Attr("_NCProperties", &out.Properties); err != nil {
+			return nil, err
+		}
+	}
+	if out.Attrs, err = readAttrs(f.File, f.Attr); err != nil {
+		return nil, err
+	}
+	keys, err := f.Keys()
+	if err != nil {
+		return nil, err
+	}
+	r := &reader{ids: make(map[string]int), phony: make(map[int]string)}
+	for _, key := range keys {
+		info, err := f.Info(core.Path(key))
+		if err != nil {
+			return nil, err
+		}
+		if info.Type != h5o.DATASET {
+			continue
+		}
+		if err := r.read(f, key, out); err != nil {
+			return nil, err
+		}
+	}
+	sort.Stable(byId{out.Dims, r.ids})
+	return out, nil
+}
+
+// The state of the reader
+type reader struct {
+	ids   map[string]int // The netCDF ids of the dimensions
+	phony map[int]string // The phony dimensions, by length
+}
+
+// Reads a dataset of the file, either as a dimension, a variable or
+// both (coordinate variable)
+func (r *reader) read(f *h5go.File, name string, out *Dataset) error {
+	ds, err := f.OpenDataset(core.Path(name))
+	if err != nil {
+		return err
+	}
+	defer ds.Close()
+	v := Var{Name: name}
+	if scale, err := ds.IsScale(); err != nil {
+		return err
+	} else if scale {
+		dim, err := r.dim(ds, name)
+		if err != nil {
+			return err
+		}
+		out.Dims = append(out.Dims, dim)
+		if sname, err := ds.ScaleName(); err != nil {
+			return err
+		} else if strings.HasPrefix(sname, phony) {
+			return nil
+		}
+		v.Dims = []string{name}
+	} else {
+		if v.Dims, err = r.dims(ds, out); err != nil {
+			return err
+		}
+	}
+	if err := readVar(ds, &v); err != nil {
+		return fmt.Errorf("Variable %s: %s", name, err)
+	}
+	out.Vars = append(out.Vars, v)
+	return nil
+}
+
+// Reads the dimension of a dimension scale
+func (r *reader) dim(ds *h5go.Dataset, name string) (Dim, error) {
+	S, err := ds.Shape()
+	if err != nil {
+		return Dim{}, err
+	}
+	defer S.Close()
+	dims, maxs, err := S.GetDims()
+	if err != nil {
+		return Dim{}, err
+	} else if len(dims) != 1 {
+		return Dim{}, fmt.Errorf("Dimension %s has %v dimensions", name, len(dims))
+	}
+	r.ids[name] = len(r.ids)
+	if ok, err := ds.HasAttr("_Netcdf4Dimid"); err != nil {
+		return Dim{}, err
+	} else if ok {
+		var id int32
+		if err := ds.Attr("_Netcdf4Dimid", &id); err != nil {
+			return Dim{}, err
+		}
+		r.ids[name] = int(id)
+	}
+	return Dim{Name: name, Len: dims[0], Unlimited: maxs[0] < 0}, nil
+}
+
+// Gets the names of the dimensions of a variable, from the scales
+// attached to its axes
+func (r *reader) dims(ds *h5go.Dataset, out *Dataset) ([]string, error) {
+	names := make([]string, len(ds.Dims()))
+	for i, n := range ds.Dims() {
+		scales, err := ds.Scales(i)
+		if err != nil {
+			return nil, err
+		}
+		if len(scales) > 0 {
+			names[i] = path.Base(string(scales[0]))
+			continue
+		}
+		name, ok := r.phony[n]
+		if !ok {
+			name = fmt.Sprintf("phony_dim_%d", len(r.phony))
+			r.phony[n] = name
+			r.ids[name] = len(r.ids) + 1<<16
+			out.Dims = append(out.Dims, Dim{Name: name, Len: n})
+		}
+		names[i] = name
+	}
+	return names, nil
+}
+
+// Reads the data, fill value and attributes of the variable
+func readVar(ds *h5go.Dataset, v *Var) error {
+	elem, err := goType(ds.Datatype())
+	if err != nil {
+		return err
+	}
+	T := elem
+	for range ds.Dims() {
+		T = reflect.SliceOf(T)
+	}
+	data := reflect.New(T)
+	if err := ds.ReadAll(data.Interface()); err != nil {
+		return err
+	}
+	v.Data = data.Elem().Interface()
+	if ok, err := ds.HasAttr("_FillValue"); err != nil {
+		return err
+	} else if ok {
+		fill := reflect.New(elem)
+		if err := ds.Attr("_FillValue", fill.Interface()); err != nil {
+			return err
+		}
+		v.Fill = fill.Elem().Interface()
+	}
+	v.Attrs, err = readAttrs(ds.Dataset, ds.Attr)
+	return err
+}
+
+// Reads the attributes of the object, except the ones used by the
+// netCDF conventions
+func readAttrs(obj core.Object,
+	get func(string, interface{}) error) ([]Attr, error) {
+	names, err := h5a.Names(obj, core.ByName, core.Increasing)
+	if err != nil {
+		return nil, err
+	}
+	attrs := make([]Attr, 0, len(names))
+	for _, name := range names {
+		if hidden[name] {
+			continue
+		}
+		value, err := readAttr(obj, name, get)
+		if err != nil {
+			return nil, fmt.Errorf("Attribute %s: %s", name, err)
+		}
+		attrs = append(attrs, Attr{Name: name, Value: value})
+	}
+	if len(attrs) == 0 {
+		return nil, nil
+	}
+	return attrs, nil
+}
+
+// Reads the value of an attribute, as a scalar if it holds a single
+// element, and as a slice otherwise. Empty text is stored without
+// elements (see text), and read as an empty string.
+func readAttr(obj core.Object, name string,
+	get func(string, interface{}) error) (interface{}, error) {
+	attr, err := h5a.Open(obj, name)
+	if err != nil {
+		return nil, err
+	}
+	defer attr.Close()
+	T, err := attr.Type()
+	if err != nil {
+		return nil, err
+	}
+	defer T.Close()
+	elem, err := goType(T)
+	if err != nil {
+		return nil, err
+	}
+	S, err := attr.Shape()
+	if err != nil {
+		return nil, err
+	}
+	defer S.Close()
+	if cls, err := S.GetClass(); err != nil {
+		return nil, err
+	} else if cls == h5s.NULL && elem.Kind() == reflect.String {
+		return "", nil
+	} else if cls == h5s.NULL {
+		return reflect.MakeSlice(reflect.SliceOf(elem), 0, 0).Interface(), nil
+	}
+	n, err := S.GetNPoints()
+	if err != nil {
+		return nil, err
+	}
+	if n != 1 {
+		elem = reflect.SliceOf(elem)
+	}
+	value := reflect.New(elem)
+	if err := get(name, value.Interface()); err != nil {
+		return nil, err
+	}
+	return value.Elem().Interface(), nil
+}
+
+// Sorts the dimensions by id
+type byId struct {
+	dims []Dim
+	ids  map[string]int
+}
+
+func (d byId) Len() int           { return len(d.dims) }
+func (d byId) Swap(i, j int)      { d.dims[i], d.dims[j] = d.dims[j], d.dims[i] }
+func (d byId) Less(i, j int) bool { return d.ids[d.dims[i].Name] < d.ids[d.dims[j].Name] }
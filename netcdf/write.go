@@ -0,0 +1,203 @@
+package netcdf
+
+import (
+	"fmt"
+	"reflect"
+)
+import (
+	"github.com/valoox/h5go"
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5t"
+)
+
+// Writes the dataset as a netCDF-4 file, overwriting any existing
+// file at this path. Its Properties are ignored: the file is marked
+// as written by this package.
+func Write(path string, ds *Dataset) error {
+	f, err := h5go.Create(path, true)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := f.SetAttr("_NCProperties", text(properties())); err != nil {
+		return err
+	}
+	for _, attr := range ds.Attrs {
+		if err := setAttr(f.SetAttr, attr); err != nil {
+			return err
+		}
+	}
+	dims := make(map[string]Dim, len(ds.Dims))
+	for _, dim := range ds.Dims {
+		if _, ok := dims[dim.Name]; ok {
+			return fmt.Errorf("Duplicate dimension %s", dim.Name)
+		} else if dim.Len < 0 {
+			return fmt.Errorf("Invalid length %v for dimension %s", dim.Len, dim.Name)
+		}
+		dims[dim.Name] = dim
+	}
+	// Dimensions come first, as the scales of the other variables
+	scales := make(map[string]*h5go.Dataset, len(ds.Dims))
+	defer func() {
+		for _, scale := range scales {
+			scale.Close()
+		}
+	}()
+	for id, dim := range ds.Dims {
+		var scale *h5go.Dataset
+		if v := coordinate(ds, dim.Name); v != nil {
+			if scale, err = writeVar(f, v, dims); err != nil {
+				return err
+			}
+			scales[dim.Name] = scale
+			err = scale.SetScale(dim.Name)
+		} else {
+			if scale, err = writeDim(f, dim); err != nil {
+				return err
+			}
+			scales[dim.Name] = scale
+			err = scale.SetScale(fmt.Sprintf("%s%10d", phony, dim.Len))
+		}
+		if err != nil {
+			return err
+		}
+		if err := scale.SetAttr("_Netcdf4Dimid", int32(id)); err != nil {
+			return err
+		}
+	}
+	for i := range ds.Vars {
+		v := &ds.Vars[i]
+		if coordinate(ds, v.Name) == v {
+			continue
+		}
+		dset, err := writeVar(f, v, dims)
+		if err != nil {
+			return err
+		}
+		for i, dim := range v.Dims {
+			if err := dset.AttachScale(i, scales[dim]); err != nil {
+				dset.Close()
+				return err
+			}
+		}
+		if err := dset.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The coordinate variable of the dimension (i.e. the one-dimensional
+// variable with the same name), or nil
+func coordinate(ds *Dataset, name string) *Var {
+	for i, v := range ds.Vars {
+		if v.Name == name && len(v.Dims) == 1 && v.Dims[0] == name {
+			return &ds.Vars[i]
+		}
+	}
+	return nil
+}
+
+// The shape of a variable, along with its maximum shape and its
+// chunks (nil if the variable has no unlimited dimension)
+func extent(v *Var, dims map[string]Dim) (shape, maxs, chunks []int, err error) {
+	shape = make([]int, len(v.Dims))
+	maxs = make([]int, len(v.Dims))
+	chunks = make([]int, len(v.Dims))
+	unlimited := false
+	for i, name := range v.Dims {
+		dim, ok := dims[name]
+		if !ok {
+			return nil, nil, nil,
+				fmt.Errorf("Unknown dimension %s of variable %s", name, v.Name)
+		}
+		shape[i], maxs[i], chunks[i] = dim.Len, dim.Len, dim.Len
+		if dim.Unlimited {
+			// Records are chunked one by one, as netCDF does
+			maxs[i], chunks[i], unlimited = -1, 1, true
+		} else if dim.Len == 0 {
+			chunks[i] = 1
+		}
+	}
+	if !unlimited {
+		return shape, nil, nil, nil
+	}
+	return shape, maxs, chunks, nil
+}
+
+// Writes a dimension which is not a variable, as an empty dataset
+func writeDim(f *h5go.File, dim Dim) (*h5go.Dataset, error) {
+	T, err := h5t.Float32()
+	if err != nil {
+		return nil, err
+	}
+	defer T.Close()
+	dims, maxs := []int{dim.Len}, []int(nil)
+	opts := []h5go.Option(nil)
+	if dim.Unlimited {
+		maxs = []int{-1}
+		opts = append(opts, h5go.Chunks(1))
+	}
+	return f.CreateDataset(core.Path(dim.Name), T, dims, maxs, opts...)
+}
+
+// Writes the variable, along with its data and attributes
+func writeVar(f *h5go.File, v *Var, dims map[string]Dim) (*h5go.Dataset, error) {
+	elem, err := elemType(v.Data)
+	if err != nil {
+		return nil, fmt.Errorf("Variable %s: %s", v.Name, err)
+	}
+	T, err := h5t.ParseType(elem, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer T.Close()
+	shape, maxs, chunks, err := extent(v, dims)
+	if err != nil {
+		return nil, err
+	}
+	opts := []h5go.Option(nil)
+	if chunks != nil {
+		opts = append(opts, h5go.Chunks(chunks...))
+	}
+	if v.Fill != nil {
+		if reflect.TypeOf(v.Fill) != elem {
+			return nil, fmt.Errorf("Invalid fill value for variable %s: expecting %s, got %T", v.Name, elem, v.Fill)
+		}
+		opts = append(opts, h5go.Fill(v.Fill))
+	}
+	dset, err := f.CreateDataset(core.Path(v.Name), T, shape, maxs,
+		opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := fillVar(dset, v); err != nil {
+		dset.Close()
+		return nil, err
+	}
+	return dset, nil
+}
+
+// Writes the data and attributes of the variable
+func fillVar(dset *h5go.Dataset, v *Var) error {
+	n := 1
+	for _, d := range dset.Dims() {
+		n *= d
+	}
+	if n > 0 {
+		if err := dset.WriteAll(v.Data); err != nil {
+			return fmt.Errorf("Variable %s: %s", v.Name, err)
+		}
+	}
+	if v.Fill != nil {
+		if err := dset.SetAttr("_FillValue", v.Fill); err != nil {
+			return err
+		}
+	}
+	for _, attr := range v.Attrs {
+		if err := setAttr(dset.SetAttr, attr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
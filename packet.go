@@ -0,0 +1,99 @@
+package h5go
+
+import (
+	"fmt"
+	"io"
+)
+import (
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5t"
+)
+
+// A packet table, in the manner of the H5PT high-level library: a
+// one-dimensional dataset of fixed-type records (the packets), which
+// are appended at its end and read either sequentially, using an
+// internal cursor, or at random.
+// Packets can be of any type convertible to the datatype of the
+// table, typically scalars or structures.
+type PacketTable struct {
+	*Dataset
+	cursor int // The index of the next packet read by Next
+}
+
+// Creates an empty packet table of the given datatype, chunked by
+// `chunk` packets. The options apply to the creation properties of
+// the dataset, e.g. to compress it
+func (l *loc) NewPacketTable(path core.Path, dtype h5t.Datatype,
+	chunk int, opts ...Option) (*PacketTable, error) {
+	ds, err := l.newAppendable(path, dtype, nil, chunk, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &PacketTable{Dataset: ds}, nil
+}
+
+// Opens an existing packet table, with its cursor on the first packet
+func (l *loc) OpenPacketTable(path core.Path) (*PacketTable, error) {
+	ds, err := l.OpenDataset(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(ds.dims) != 1 {
+		ds.Close()
+		return nil, fmt.Errorf("%s is not a packet table: expecting 1 dimension, got %v", path, len(ds.dims))
+	}
+	return &PacketTable{Dataset: ds}, nil
+}
+
+// The number of packets in the table
+func (p *PacketTable) Len() int { return p.dims[0] }
+
+// Appends the packets (a slice of records, or a single record) at
+// the end of the table
+func (p *PacketTable) Append(packets interface{}) error {
+	buf, err := newBuffer(packets, p.in)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+	if len(buf.dims) == 0 {
+		buf.dims = []int{1}
+	}
+	return p.extend(buf)
+}
+
+// Reads `n` packets, starting at `index`, into the value pointed by
+// `out` (typically a slice of records). This does not move the cursor
+func (p *PacketTable) Get(index, n int, out interface{}) error {
+	return p.readRows(index, n, out)
+}
+
+// Reads the next packets (at most `n`) into the value pointed by
+// `out`, and moves the cursor past them. Returns the number of
+// packets read, or io.EOF when all packets have already been read
+func (p *PacketTable) Next(n int, out interface{}) (int, error) {
+	left := p.Len() - p.cursor
+	if left <= 0 {
+		return 0, io.EOF
+	}
+	if n > left {
+		n = left
+	}
+	if err := p.readRows(p.cursor, n, out); err != nil {
+		return 0, err
+	}
+	p.cursor += n
+	return n, nil
+}
+
+// The index of the next packet read by Next
+func (p *PacketTable) Index() int { return p.cursor }
+
+// Moves the cursor to the given packet
+func (p *PacketTable) SetIndex(index int) error {
+	if index < 0 || index > p.Len() {
+		return fmt.Errorf("Index %v out of range for %v packets", index, p.Len())
+	}
+	p.cursor = index
+	return nil
+}
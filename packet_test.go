@@ -0,0 +1,74 @@
+package h5go
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"testing"
+)
+import "github.com/valoox/h5go/h5t"
+
+// A sensor reading, logged as a packet
+type reading struct {
+	Sensor uint16
+	Value  float32
+}
+
+// Appends packets and reads them back sequentially and at random
+func TestPacketTable(t *testing.T) {
+	const testfile = "./packets.h5"
+	f, err := Create(testfile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(testfile)
+	defer f.Close()
+	T, err := h5t.Parse(reading{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer T.Close()
+	pt, err := f.NewPacketTable("log", T, 8, Deflate(6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pt.Close()
+	for i := 0; i < 5; i++ {
+		if err := pt.Append(reading{uint16(i), float32(i) / 2}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pt.Append([]reading{{5, 2.5}, {6, 3}}); err != nil {
+		t.Fatal(err)
+	}
+	if n := pt.Len(); n != 7 {
+		t.Fatalf("Expected 7 packets, got %v", n)
+	}
+	var all []reading
+	for {
+		var packets []reading
+		n, err := pt.Next(3, &packets)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if n != len(packets) {
+			t.Fatalf("Expected %v packets, got %v", n, len(packets))
+		}
+		all = append(all, packets...)
+	}
+	if len(all) != 7 || all[6] != (reading{6, 3}) {
+		t.Fatalf("Wrong packets: %v", all)
+	}
+	var packets []reading
+	if err := pt.Get(2, 2, &packets); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(packets, []reading{{2, 1}, {3, 1.5}}) {
+		t.Fatalf("Wrong packets: %v", packets)
+	}
+	if err := pt.SetIndex(8); err == nil {
+		t.Fatal("Expected an error moving the cursor past the end")
+	}
+}
@@ -0,0 +1,61 @@
+package h5go
+
+import (
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5d"
+	"github.com/valoox/h5go/h5ds"
+	"github.com/valoox/h5go/h5i"
+)
+
+// Turns the dataset into a dimension scale with the given name, so
+// that it can be attached to the dimensions of other datasets
+// (see h5ds.SetScale)
+func (d *Dataset) SetScale(name string) error {
+	return h5ds.SetScale(d.Dataset, name)
+}
+
+// Checks whether the dataset is a dimension scale
+func (d *Dataset) IsScale() (bool, error) {
+	return h5ds.IsScale(d.Dataset)
+}
+
+// The name of the dimension scale
+func (d *Dataset) ScaleName() (string, error) {
+	return h5ds.GetScaleName(d.Dataset)
+}
+
+// Attaches the dimension scale to the dimension `dim` of the dataset
+func (d *Dataset) AttachScale(dim int, scale *Dataset) error {
+	return h5ds.Attach(d.Dataset, scale.Dataset, dim)
+}
+
+// Detaches the dimension scale from the dimension `dim` of the dataset
+func (d *Dataset) DetachScale(dim int, scale *Dataset) error {
+	return h5ds.Detach(d.Dataset, scale.Dataset, dim)
+}
+
+// The paths of the scales attached to the dimension `dim` of the
+// dataset, in the order they were attached
+func (d *Dataset) Scales(dim int) ([]core.Path, error) {
+	paths := make([]core.Path, 0, 1)
+	err := h5ds.Iterate(d.Dataset, dim, func(scale h5d.Dataset) error {
+		path, err := h5i.GetName(scale.Id())
+		if err != nil {
+			return err
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	return paths, err
+}
+
+// Sets the label of the dimension `dim` of the dataset
+func (d *Dataset) SetLabel(dim int, label string) error {
+	return h5ds.SetLabel(d.Dataset, dim, label)
+}
+
+// The label of the dimension `dim` of the dataset, which is empty if
+// none was set
+func (d *Dataset) Label(dim int) (string, error) {
+	return h5ds.GetLabel(d.Dataset, dim)
+}
@@ -0,0 +1,60 @@
+package h5go
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+import "github.com/valoox/h5go/core"
+
+// Attaches dimension scales and labels to the axes of a dataset
+func TestScales(t *testing.T) {
+	const testfile = "./scales.h5"
+	f, err := Create(testfile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(testfile)
+	defer f.Close()
+	data, err := f.NewDataset("temperature", [][]float32{{1, 2, 3}, {4, 5, 6}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer data.Close()
+	lat, err := f.NewDataset("lat", []float64{45, 46})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lat.Close()
+	if err := lat.SetScale("latitude"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := lat.IsScale(); err != nil || !ok {
+		t.Fatalf("Expected a dimension scale (%v)", err)
+	}
+	if name, err := lat.ScaleName(); err != nil || name != "latitude" {
+		t.Fatalf("Wrong scale name: %q (%v)", name, err)
+	}
+	if err := data.AttachScale(0, lat); err != nil {
+		t.Fatal(err)
+	}
+	if err := data.SetLabel(1, "x"); err != nil {
+		t.Fatal(err)
+	}
+	scales, err := data.Scales(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(scales, []core.Path{"/lat"}) {
+		t.Fatalf("Wrong scales: %v", scales)
+	}
+	if label, err := data.Label(1); err != nil || label != "x" {
+		t.Fatalf("Wrong label: %q (%v)", label, err)
+	}
+	if err := data.DetachScale(0, lat); err != nil {
+		t.Fatal(err)
+	}
+	if scales, err := data.Scales(0); err != nil || len(scales) != 0 {
+		t.Fatalf("Expected no scale, got %v (%v)", scales, err)
+	}
+}
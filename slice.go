@@ -0,0 +1,326 @@
+package h5go
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+import (
+	"github.com/valoox/h5go/h5d"
+	"github.com/valoox/h5go/h5s"
+)
+
+// Selects elements along one dimension of a dataset, following the
+// numpy conventions: either a range `start:stop:step` (negative
+// bounds counting from the end of the dimension), a single index
+// (which drops the dimension from the result) or an ellipsis
+// (standing for as many full ranges as needed)
+type Slice struct {
+	start, stop, step int  // The bounds of the range
+	from, to          bool // Whether start and stop were provided
+	index             bool // Whether this is a single index
+	ellipsis          bool // Whether this is an ellipsis
+}
+
+// The ellipsis (`...`), expanding to full ranges over all the
+// dimensions which are not otherwise sliced
+var Ellipsis = Slice{ellipsis: true}
+
+// Creates a range over a dimension: S() is the full range (`:`),
+// S(start) runs until the end (`start:`) and S(start, stop) runs
+// from start (included) to stop (excluded). The step can be set
+// using the Step method
+func S(bounds ...int) Slice {
+	out := Slice{step: 1}
+	if len(bounds) > 0 {
+		out.start, out.from = bounds[0], true
+	}
+	if len(bounds) > 1 {
+		out.stop, out.to = bounds[1], true
+	}
+	return out
+}
+
+// Selects a single index of a dimension, which is dropped from the
+// shape of the result
+func At(i int) Slice { return Slice{start: i, index: true} }
+
+// Sets the step of the range. Only positive steps are supported
+func (s Slice) Step(step int) Slice {
+	s.step = step
+	return s
+}
+
+// Textual representation of the slice, in numpy syntax
+func (s Slice) String() string {
+	switch {
+	case s.ellipsis:
+		return "..."
+	case s.index:
+		return strconv.Itoa(s.start)
+	}
+	out := ""
+	if s.from {
+		out += strconv.Itoa(s.start)
+	}
+	out += ":"
+	if s.to {
+		out += strconv.Itoa(s.stop)
+	}
+	if s.step != 1 {
+		out += ":" + strconv.Itoa(s.step)
+	}
+	return out
+}
+
+// Parses a comma-separated list of slices in numpy syntax,
+// e.g. "10:20, ::2, 5" or "..., -1"
+func ParseSlices(expr string) ([]Slice, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+	parts := strings.Split(expr, ",")
+	out := make([]Slice, len(parts))
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "..." {
+			out[i] = Ellipsis
+			continue
+		}
+		bounds := strings.Split(part, ":")
+		if len(bounds) > 3 {
+			return nil, fmt.Errorf("Invalid slice: %q", part)
+		}
+		vals := make([]int, len(bounds))
+		set := make([]bool, len(bounds))
+		for j, b := range bounds {
+			if b = strings.TrimSpace(b); b == "" {
+				continue
+			}
+			v, err := strconv.Atoi(b)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid slice: %q", part)
+			}
+			vals[j], set[j] = v, true
+		}
+		if len(bounds) == 1 {
+			if !set[0] {
+				return nil, fmt.Errorf("Empty slice at position %v", i)
+			}
+			out[i] = At(vals[0])
+			continue
+		}
+		out[i] = Slice{
+			start: vals[0], from: set[0],
+			stop: vals[1], to: set[1],
+			step: 1,
+		}
+		if len(bounds) == 3 && set[2] {
+			out[i].step = vals[2]
+		}
+	}
+	return out, nil
+}
+
+// Resolves the slices against the dimensions of a dataspace, returning
+// the hyperslab (start, stride and count for each dimension) and the
+// shape of the result, where dimensions selected by an index are
+// dropped
+func resolve(slices []Slice, dims []int) (start, stride, count []uint, shape []int, err error) {
+	// Expands the ellipsis (or pads with full ranges)
+	full := make([]Slice, 0, len(dims))
+	ellipsis := false
+	for _, s := range slices {
+		if !s.ellipsis {
+			full = append(full, s)
+			continue
+		}
+		if ellipsis {
+			return nil, nil, nil, nil,
+				fmt.Errorf("Only one ellipsis is allowed")
+		}
+		ellipsis = true
+		for n := len(dims) - (len(slices) - 1); n > 0; n-- {
+			full = append(full, S())
+		}
+	}
+	if len(full) > len(dims) {
+		return nil, nil, nil, nil,
+			fmt.Errorf("Too many indices: %v for %v dimensions", len(full), len(dims))
+	}
+	for len(full) < len(dims) {
+		full = append(full, S())
+	}
+	start = make([]uint, len(dims))
+	stride = make([]uint, len(dims))
+	count = make([]uint, len(dims))
+	shape = make([]int, 0, len(dims))
+	for i, s := range full {
+		n := dims[i]
+		if s.index {
+			k := s.start
+			if k < 0 {
+				k += n
+			}
+			if k < 0 || k >= n {
+				return nil, nil, nil, nil,
+					fmt.Errorf("Index %v out of range for dimension %v of size %v", s.start, i, n)
+			}
+			start[i], stride[i], count[i] = uint(k), 1, 1
+			continue
+		}
+		if s.step <= 0 {
+			return nil, nil, nil, nil,
+				fmt.Errorf("Invalid step %v for dimension %v: must be positive", s.step, i)
+		}
+		lo, hi := 0, n
+		if s.from {
+			lo = clamp(s.start, n)
+		}
+		if s.to {
+			hi = clamp(s.stop, n)
+		}
+		c := 0
+		if hi > lo {
+			c = (hi - lo + s.step - 1) / s.step
+		}
+		start[i], stride[i], count[i] = uint(lo), uint(s.step), uint(c)
+		shape = append(shape, c)
+	}
+	return
+}
+
+// Converts a (possibly negative) bound to a position in [0, n]
+func clamp(k, n int) int {
+	if k < 0 {
+		k += n
+	}
+	if k < 0 {
+		return 0
+	} else if k > n {
+		return n
+	}
+	return k
+}
+
+// A selection of the elements of a dataset, along with the shape of
+// the selected data
+type Selection struct {
+	ds    *Dataset      // The dataset
+	space h5s.Hyperslab // The dataspace of the dataset, with the selection
+	shape []int         // The shape of the selected data
+}
+
+// Selects the elements of the dataset using slices in numpy syntax,
+// e.g. ds.Slice("10:20, ::2, 5"). The selection should be closed
+// after use.
+func (d *Dataset) Slice(expr string) (*Selection, error) {
+	slices, err := ParseSlices(expr)
+	if err != nil {
+		return nil, err
+	}
+	return d.Select(slices...)
+}
+
+// Selects the elements of the dataset along each dimension, e.g.
+// ds.Select(S(10, 20), S().Step(2), At(5)). Missing trailing slices
+// select entire dimensions. The selection should be closed after use.
+func (d *Dataset) Select(slices ...Slice) (*Selection, error) {
+	space, err := d.Shape()
+	if err != nil {
+		return nil, err
+	}
+	dims, _, err := space.GetDims()
+	if err != nil {
+		space.Close()
+		return nil, err
+	}
+	start, stride, count, shape, err := resolve(slices, dims)
+	if err != nil {
+		space.Close()
+		return nil, err
+	}
+	out := &Selection{
+		ds:    d,
+		space: h5s.Hyperslab(space),
+		shape: shape,
+	}
+	if len(dims) > 0 {
+		if err := out.space.Set(start, stride, count, nil); err != nil {
+			out.Close()
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// The shape of the selected data. Dimensions selected by an index
+// are dropped
+func (s *Selection) Shape() []int { return s.shape }
+
+// The dataspace of the dataset, where the elements are selected
+func (s *Selection) Selection() h5s.Dataspace { return s.space.Selection() }
+
+// Creates the memory dataspace matching the shape of the selection,
+// which should be closed after use
+func (s *Selection) Memory() (h5s.Dataspace, error) {
+	if len(s.shape) == 0 {
+		return h5s.CreateScalar()
+	}
+	return h5s.CreateSimple(s.shape, nil)
+}
+
+// Reads the selected elements into the value pointed by `out`, which
+// is allocated to the shape of the selection (see ReadAll)
+func (s *Selection) Read(out interface{}) error {
+	return readInto(s.ds.dtype, s.shape, s.ds.in,
+		func(buf h5d.OBuffer) error {
+			return s.ds.Read(buf, s.Selection(), h5d.DefaultXfer)
+		}, out)
+}
+
+// Writes the data to the selected elements. The data must hold as
+// many elements as selected
+func (s *Selection) Write(data interface{}) error {
+	buf, err := newBuffer(data, s.ds.in)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+	if n, expected := count(buf.dims), count(s.shape); n != expected {
+		return fmt.Errorf("Expecting %v elements, got %v", expected, n)
+	}
+	return s.ds.Write(buf, s.Selection(), h5d.DefaultXfer)
+}
+
+// Reads the selected elements like Read, piece by piece so that the
+// read can be cancelled through the context, reporting the progress
+// (if not nil) after each piece (see h5d.Dataset.ReadContext)
+func (s *Selection) ReadContext(ctx context.Context, out interface{},
+	progress h5d.Progress) error {
+	return readInto(s.ds.dtype, s.shape, s.ds.in,
+		func(buf h5d.OBuffer) error {
+			return s.ds.Dataset.ReadContext(ctx, buf, s.Selection(),
+				h5d.DefaultXfer, progress)
+		}, out)
+}
+
+// Writes the data to the selected elements like Write, piece by
+// piece (see ReadContext)
+func (s *Selection) WriteContext(ctx context.Context, data interface{},
+	progress h5d.Progress) error {
+	buf, err := newBuffer(data, s.ds.in)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+	if n, expected := count(buf.dims), count(s.shape); n != expected {
+		return fmt.Errorf("Expecting %v elements, got %v", expected, n)
+	}
+	return s.ds.Dataset.WriteContext(ctx, buf, s.Selection(),
+		h5d.DefaultXfer, progress)
+}
+
+// Releases the dataspace of the selection
+func (s *Selection) Close() error { return s.space.Dataspace().Close() }
@@ -0,0 +1,176 @@
+package h5go
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+)
+import (
+	"github.com/valoox/h5go/h5d"
+)
+
+// Parses slices in numpy syntax
+func TestParseSlices(t *testing.T) {
+	slices, err := ParseSlices("10:20, ::2, 5, ..., -3:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Slice{S(10, 20), S().Step(2), At(5), Ellipsis, S(-3)}
+	if !reflect.DeepEqual(slices, expected) {
+		t.Fatalf("Expected %v, got %v", expected, slices)
+	}
+	for _, bad := range []string{"1:2:3:4", "a:b", "1,,2"} {
+		if _, err := ParseSlices(bad); err == nil {
+			t.Errorf("Expected an error parsing %q", bad)
+		}
+	}
+}
+
+// Resolves the slices against the dimensions
+func TestResolve(t *testing.T) {
+	dims := []int{100, 10, 8}
+	start, stride, count, shape, err := resolve(
+		[]Slice{S(-10), At(-1)}, dims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(start, []uint{90, 9, 0}) ||
+		!reflect.DeepEqual(stride, []uint{1, 1, 1}) ||
+		!reflect.DeepEqual(count, []uint{10, 1, 8}) ||
+		!reflect.DeepEqual(shape, []int{10, 8}) {
+		t.Fatalf("Wrong hyperslab: %v %v %v %v", start, stride, count, shape)
+	}
+	// Ellipsis, steps and clamping
+	start, stride, count, shape, err = resolve(
+		[]Slice{Ellipsis, S(1, 100).Step(3)}, dims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(start, []uint{0, 0, 1}) ||
+		!reflect.DeepEqual(stride, []uint{1, 1, 3}) ||
+		!reflect.DeepEqual(count, []uint{100, 10, 3}) ||
+		!reflect.DeepEqual(shape, []int{100, 10, 3}) {
+		t.Fatalf("Wrong hyperslab: %v %v %v %v", start, stride, count, shape)
+	}
+	for _, bad := range [][]Slice{
+		{At(100)},
+		{At(0), At(0), At(0), At(0)},
+		{Ellipsis, Ellipsis},
+		{S().Step(0)},
+	} {
+		if _, _, _, _, err := resolve(bad, dims); err == nil {
+			t.Errorf("Expected an error resolving %v", bad)
+		}
+	}
+}
+
+// Reads and writes slices of a dataset
+func TestSlice(t *testing.T) {
+	const testfile = "./slice.h5"
+	f, err := Create(testfile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(testfile)
+	defer f.Close()
+	data := make([][]int32, 6)
+	for i := range data {
+		data[i] = make([]int32, 4)
+		for j := range data[i] {
+			data[i][j] = int32(10*i + j)
+		}
+	}
+	ds, err := f.NewDataset("ints", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ds.Close()
+	sel, err := ds.Slice("1:-1:2, -1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sel.Close()
+	var col []int32
+	if err := sel.Read(&col); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(col, []int32{13, 33}) {
+		t.Fatalf("Wrong slice: %v", col)
+	}
+	if err := sel.Write([]int32{-1, -3}); err != nil {
+		t.Fatal(err)
+	}
+	pt, err := ds.Select(At(3), At(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pt.Close()
+	var x int32
+	if err := pt.Read(&x); err != nil {
+		t.Fatal(err)
+	}
+	if x != -3 {
+		t.Fatalf("Expected -3, got %v", x)
+	}
+}
+
+// Reads and writes a selection piece by piece
+func TestSelectionContext(t *testing.T) {
+	const testfile = "./context.h5"
+	f, err := Create(testfile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(testfile)
+	defer f.Close()
+	data := make([][]int32, 100)
+	for i := range data {
+		data[i] = []int32{int32(i), int32(2 * i), int32(3 * i)}
+	}
+	ds, err := f.NewDataset("rows", data, Chunks(10, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ds.Close()
+	// Pieces of two chunks
+	defer func(size int) { h5d.PieceSize = size }(h5d.PieceSize)
+	h5d.PieceSize = 2 * 10 * 3 * 4
+	sel, err := ds.Slice("5:95, 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sel.Close()
+	var steps []int
+	var col []int32
+	if err := sel.ReadContext(context.Background(), &col,
+		func(done, total int) {
+			if total != 90 {
+				t.Errorf("Wrong total: %v", total)
+			}
+			steps = append(steps, done)
+		}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(steps, []int{15, 35, 55, 75, 90}) {
+		t.Errorf("Wrong progress: %v", steps)
+	}
+	for i, x := range col {
+		if x != int32(2*(i+5)) {
+			t.Fatalf("Wrong value at %v: %v", i, x)
+		}
+	}
+	// Cancelled after the first piece
+	ctx, cancel := context.WithCancel(context.Background())
+	zeros := make([]int32, 90)
+	err = sel.WriteContext(ctx, zeros, func(done, total int) { cancel() })
+	if err != context.Canceled {
+		t.Fatalf("Expected the write to be cancelled, got %v", err)
+	}
+	if err := sel.Read(&col); err != nil {
+		t.Fatal(err)
+	}
+	if col[14] != 0 || col[15] != 40 {
+		t.Errorf("Wrong values written: %v", col[10:20])
+	}
+}
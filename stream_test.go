@@ -0,0 +1,127 @@
+package h5go
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+import (
+	"github.com/valoox/h5go/h5d"
+	"github.com/valoox/h5go/h5t"
+)
+
+// Streams bytes in and out of a growing dataset
+func TestStream(t *testing.T) {
+	const testfile = "./stream.h5"
+	f, err := Create(testfile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(testfile)
+	defer f.Close()
+	T, err := h5t.Uint8()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer T.Close()
+	ds, err := f.CreateDataset("blob", T, []int{0}, []int{-1}, Chunks(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ds.Close()
+	s, err := h5d.NewStream(ds.Dataset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := io.WriteString(s, "hello, world"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.WriteAt([]byte("W"), 7); err != nil {
+		t.Fatal(err)
+	}
+	if s.Size() != 12 {
+		t.Fatalf("Wrong size: %v", s.Size())
+	}
+	if _, err := s.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	all, err := ioutil.ReadAll(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(all) != "hello, World" {
+		t.Fatalf("Wrong content: %q", all)
+	}
+	buf := make([]byte, 8)
+	if n, err := s.ReadAt(buf, 7); n != 5 || err != io.EOF {
+		t.Fatalf("Expected 5 bytes and EOF, got %v (%v)", n, err)
+	}
+	if string(buf[:5]) != "World" {
+		t.Fatalf("Wrong content: %q", buf[:5])
+	}
+	// Extending the dataset through another handle
+	other, err := f.OpenDataset("blob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	if err := other.SetDims([]int{13}); err != nil {
+		t.Fatal(err)
+	}
+	if s.Size() != 13 {
+		t.Fatalf("Expected the size of the extended dataset, got %v", s.Size())
+	}
+	if n, err := s.ReadAt(buf, 7); n != 6 || err != io.EOF {
+		t.Fatalf("Expected 6 bytes and EOF, got %v (%v)", n, err)
+	}
+	if string(buf[:6]) != "World\x00" {
+		t.Fatalf("Wrong content: %q", buf[:6])
+	}
+}
+
+// Streams bytes over opaque values of several bytes
+func TestStreamOpaque(t *testing.T) {
+	const testfile = "./opaque.h5"
+	f, err := Create(testfile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(testfile)
+	defer f.Close()
+	T, err := h5t.RawBin(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer T.Close()
+	ds, err := f.CreateDataset("blob", T, []int{0}, []int{3}, Chunks(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ds.Close()
+	s, err := h5d.NewStream(ds.Dataset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.WriteAt([]byte("abcdef"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.WriteAt([]byte("X"), 2); err != nil {
+		t.Fatal(err)
+	}
+	if s.Size() != 8 {
+		t.Fatalf("Wrong size: %v", s.Size())
+	}
+	buf := make([]byte, 8)
+	if _, err := s.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "\x00aXcdef\x00" {
+		t.Fatalf("Wrong content: %q", buf)
+	}
+	if _, err := s.WriteAt([]byte("overflow"), 8); err == nil {
+		t.Fatal("Expected an error extending past the maximum size")
+	}
+}
@@ -0,0 +1,133 @@
+package h5go
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The environment variable giving the file to the writer process
+const swmrEnv = "H5GO_SWMR_FILE"
+
+// The number of rows written by the writer process
+const swmrRows = 100
+
+// Writes rows to a file in SWMR mode. This only runs as the writer
+// process started by TestSWMR
+func TestSWMRWriter(t *testing.T) {
+	path := os.Getenv(swmrEnv)
+	if path == "" {
+		t.Skip("Only runs as the writer of TestSWMR")
+	}
+	f, err := CreateFile(path, true, &FileOptions{Latest: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	app, err := f.NewAppender("counter", int64(0), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Close()
+	if err := f.StartSWMRWrite(); err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println("ready")
+	for i := 0; i < swmrRows; i++ {
+		if err := app.Append(int64(i)); err != nil {
+			t.Fatal(err)
+		}
+		if (i+1)%10 == 0 {
+			if err := app.Dataset().Flush(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+// Reads a file while another process is writing it
+func TestSWMR(t *testing.T) {
+	const testfile = "./swmr.h5"
+	defer os.Remove(testfile)
+	writer := exec.Command(os.Args[0], "-test.run=^TestSWMRWriter$")
+	writer.Env = append(os.Environ(), swmrEnv+"="+testfile)
+	out, err := writer.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Stderr = writer.Stdout
+	if err := writer.Start(); err != nil {
+		t.Fatal(err)
+	}
+	ready, drained := make(chan bool), make(chan bool)
+	var output []string
+	go func() {
+		defer close(drained)
+		lines := bufio.NewScanner(out)
+		for lines.Scan() {
+			if lines.Text() == "ready" {
+				close(ready)
+			}
+			output = append(output, lines.Text())
+		}
+	}()
+	select {
+	case <-ready:
+	case <-time.After(30 * time.Second):
+		writer.Process.Kill()
+		t.Fatal("The writer did not start")
+	}
+	done := make(chan error, 1)
+	go func() {
+		<-drained
+		done <- writer.Wait()
+	}()
+	f, err := OpenFile(testfile, false, &FileOptions{SWMR: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	ds, err := f.OpenDataset("counter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ds.Close()
+	seen, finished := 0, false
+	for !finished {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("Writer failed: %s\n%s", err, strings.Join(output, "\n"))
+			}
+			finished = true
+		case <-time.After(5 * time.Millisecond):
+		}
+		if err := ds.Refresh(); err != nil {
+			t.Fatal(err)
+		}
+		n := ds.Dims()[0]
+		if n < seen {
+			t.Fatalf("The dataset shrank from %v to %v rows", seen, n)
+		}
+		// The extent can be seen before the rows are flushed, which
+		// then read as the fill value until the writer is done
+		var rows []int64
+		if err := ds.ReadAll(&rows); err != nil {
+			t.Fatal(err)
+		}
+		for i, x := range rows {
+			if x != int64(i) && (finished || x != 0) {
+				t.Fatalf("Wrong row %v: %v", i, x)
+			}
+		}
+		seen = n
+	}
+	if seen != swmrRows {
+		t.Fatalf("Expected %v rows, got %v", swmrRows, seen)
+	}
+}
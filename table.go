@@ -0,0 +1,207 @@
+package h5go
+
+import (
+	"fmt"
+	"reflect"
+)
+import (
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5d"
+	"github.com/valoox/h5go/h5t"
+)
+
+// The attributes identifying a table, as written by the H5TB
+// high-level library (and read by PyTables and HDFView)
+const (
+	tableClass   = "TABLE"
+	tableVersion = "3.0"
+)
+
+// A table is a one-dimensional dataset of compound elements (the
+// rows), each field of the compound being a column. Tables are
+// stored following the layout of the H5TB high-level library, so
+// that they are recognised as such by PyTables and HDFView.
+// Rows are read and written from slices of structures, whose fields
+// are matched by name with the columns of the table (see h5t.Parse).
+type Table struct {
+	*Dataset
+}
+
+// Creates a new table at this location, holding the rows provided
+// (a slice of structures, which can be empty). The columns of the
+// table are the fields of the structure.
+// The table is chunked by `chunk` rows, so that rows can later be
+// appended; the options then apply to its creation properties.
+func (l *loc) NewTable(path core.Path, title string, rows interface{},
+	chunk int, opts ...Option) (*Table, error) {
+	v := reflect.ValueOf(rows)
+	if !v.IsValid() {
+		return nil, fmt.Errorf("Nothing provided")
+	}
+	dims, elem := shapeof(v)
+	if len(dims) != 1 || elem.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Expecting a slice of structures, got %T", rows)
+	}
+	T, err := h5t.ParseType(elem, l.where)
+	if err != nil {
+		return nil, err
+	}
+	defer T.Close()
+	ds, err := l.newAppendable(path, T, nil, chunk, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := &Table{ds}
+	if err := out.init(title); err != nil {
+		out.Close()
+		return nil, err
+	}
+	if dims[0] > 0 {
+		if err := out.Append(rows); err != nil {
+			out.Close()
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Writes the attributes marking the dataset as a table
+func (t *Table) init(title string) error {
+	if err := writeattr(t.Dataset, "CLASS",
+		NewFixedString(tableClass)); err != nil {
+		return err
+	}
+	if err := writeattr(t.Dataset, "VERSION",
+		NewFixedString(tableVersion)); err != nil {
+		return err
+	}
+	if err := writeattr(t.Dataset, "TITLE",
+		NewFixedString(title)); err != nil {
+		return err
+	}
+	fields, err := t.Fields()
+	if err != nil {
+		return err
+	}
+	for i, name := range fields {
+		if err := writeattr(t.Dataset, fmt.Sprintf("FIELD_%d_NAME", i),
+			NewFixedString(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Opens the existing table at the given path from this location
+func (l *loc) OpenTable(path core.Path) (*Table, error) {
+	ds, err := l.OpenDataset(path)
+	if err != nil {
+		return nil, err
+	}
+	var class string
+	if err := ds.Attr("CLASS", &class); err != nil || class != tableClass {
+		ds.Close()
+		return nil, fmt.Errorf("%s is not a table", path)
+	}
+	if cls, err := ds.dtype.GetClass(); err != nil {
+		ds.Close()
+		return nil, err
+	} else if cls != h5t.COMPOUND || len(ds.dims) != 1 {
+		ds.Close()
+		return nil, fmt.Errorf("%s is not a table of compound rows", path)
+	}
+	return &Table{ds}, nil
+}
+
+// The title of the table
+func (t *Table) Title() (string, error) {
+	var title string
+	return title, t.Attr("TITLE", &title)
+}
+
+// The names of the columns of the table
+func (t *Table) Fields() ([]string, error) {
+	fields, err := t.dtype.GetFields()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(fields))
+	for i, fld := range fields {
+		names[i] = fld.Name
+		fld.Type.Close()
+	}
+	return names, nil
+}
+
+// The number of rows in the table
+func (t *Table) Len() int { return t.dims[0] }
+
+// Appends the rows (a slice of structures, or a single structure) at
+// the end of the table
+func (t *Table) Append(rows interface{}) error {
+	buf, err := newBuffer(rows, t.in)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+	if len(buf.dims) == 0 {
+		buf.dims = []int{1}
+	}
+	return t.extend(buf)
+}
+
+// Reads `n` rows of the table, starting at `start`, into the value
+// pointed by `out` (typically a slice of structures)
+func (t *Table) Read(start, n int, out interface{}) error {
+	return t.readRows(start, n, out)
+}
+
+// Reads `n` values of a column, starting at row `start`, into the
+// value pointed by `out` (typically a slice of the type of the field)
+func (t *Table) ReadColumn(name string, start, n int, out interface{}) error {
+	i, err := t.dtype.GetMemberIndex(name)
+	if err != nil {
+		return err
+	}
+	T, err := t.dtype.GetMemberType(i)
+	if err != nil {
+		return err
+	}
+	defer T.Close()
+	S, err := t.rows(start, n)
+	if err != nil {
+		return err
+	}
+	defer S.Close()
+	return readInto(T, []int{n}, t.in, func(buf h5d.OBuffer) error {
+		if err := buf.(*buffer).field(name); err != nil {
+			return err
+		}
+		return t.Dataset.Read(buf, S, h5d.DefaultXfer)
+	}, out)
+}
+
+// Writes the values (a slice of the type of the field) to a column,
+// starting at row `start`. The other columns are left untouched
+func (t *Table) WriteColumn(name string, start int, data interface{}) error {
+	if _, err := t.dtype.GetMemberIndex(name); err != nil {
+		return err
+	}
+	buf, err := newBuffer(data, t.in)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+	if len(buf.dims) != 1 {
+		return fmt.Errorf("Expecting a slice of values, got %T", data)
+	}
+	if err := buf.field(name); err != nil {
+		return err
+	}
+	S, err := t.rows(start, buf.dims[0])
+	if err != nil {
+		return err
+	}
+	defer S.Close()
+	return t.Write(buf, S, h5d.DefaultXfer)
+}
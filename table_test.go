@@ -0,0 +1,88 @@
+package h5go
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+// An event, stored as a row of a table
+type event struct {
+	Id    int32   `hdf:"id"`
+	Name  string  `hdf:"name"`
+	Value float64 `hdf:"value"`
+}
+
+// Creates a table, appends rows and reads rows and columns
+func TestTable(t *testing.T) {
+	const testfile = "./table.h5"
+	f, err := Create(testfile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(testfile)
+	defer f.Close()
+	events := []event{{1, "a", 0.5}, {2, "b", 1.5}}
+	tbl, err := f.NewTable("events", "Events", events, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Append([]event{{3, "c", 2.5}, {4, "d", 3.5}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Append(event{5, "e", 4.5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Close(); err != nil {
+		t.Fatal(err)
+	}
+	tbl, err = f.OpenTable("events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tbl.Close()
+	if n := tbl.Len(); n != 5 {
+		t.Fatalf("Expected 5 rows, got %v", n)
+	}
+	if title, err := tbl.Title(); err != nil || title != "Events" {
+		t.Fatalf("Wrong title: %q (%v)", title, err)
+	}
+	var name string
+	if err := tbl.Attr("FIELD_1_NAME", &name); err != nil || name != "name" {
+		t.Fatalf("Wrong field name: %q (%v)", name, err)
+	}
+	fields, err := tbl.Fields()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fields, []string{"id", "name", "value"}) {
+		t.Fatalf("Wrong fields: %v", fields)
+	}
+	var rows []event
+	if err := tbl.Read(1, 3, &rows); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rows, []event{{2, "b", 1.5}, {3, "c", 2.5}, {4, "d", 3.5}}) {
+		t.Fatalf("Wrong rows: %v", rows)
+	}
+	if err := tbl.WriteColumn("value", 3, []float64{-1, -2}); err != nil {
+		t.Fatal(err)
+	}
+	var values []float64
+	if err := tbl.ReadColumn("value", 0, 5, &values); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(values, []float64{0.5, 1.5, 2.5, -1, -2}) {
+		t.Fatalf("Wrong column: %v", values)
+	}
+	var names []string
+	if err := tbl.ReadColumn("name", 3, 2, &names); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"d", "e"}) {
+		t.Fatalf("Wrong column: %v", names)
+	}
+	if err := tbl.Read(4, 2, &rows); err == nil {
+		t.Fatal("Expected an error reading past the end")
+	}
+}
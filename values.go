@@ -0,0 +1,567 @@
+package h5go
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+import (
+	"github.com/valoox/h5go/core"
+	"github.com/valoox/h5go/h5d"
+	"github.com/valoox/h5go/h5s"
+	"github.com/valoox/h5go/h5t"
+)
+
+// Wraps a raw id into a core.Object
+type handle core.Id
+
+// The id of the object
+func (h handle) Id() core.Id { return core.Id(h) }
+
+// A contiguous buffer holding the elements of an arbitrary Go value
+// (scalar, slice, array or nested slices/arrays), which can be handed
+// to the library as an h5d.Buffer.
+// The nesting of slices and arrays is interpreted as the shape of
+// the data, while the innermost type is the type of the elements.
+// Strings are handled separately, as their memory representation in
+// Go differs from the one expected by the library.
+type buffer struct {
+	dims  []int            // The shape of the data (empty for scalars)
+	elem  reflect.Type     // The Go type of the elements
+	mem   reflect.Type     // The C layout of the elements, or nil
+	raw   reflect.Value    // The flat slice of memory given to HDF5
+	base  h5t.Datatype     // Memory type imposed by the file, or -1
+	ctxt  core.Location    // Location used to look up named types
+	owned bool             // Whether raw holds C strings to release
+	pad   h5t.Pad          // The padding of fixed-length strings read
+	cstrs []unsafe.Pointer // The C strings of the structures written
+}
+
+// The shape and element type of the value. Types implementing
+// h5t.Typed are elements, even if they are arrays (e.g. the region
+// references of h5r).
+func shapeof(v reflect.Value) ([]int, reflect.Type) {
+	dims := make([]int, 0, 4)
+	T := v.Type()
+	for (T.Kind() == reflect.Slice || T.Kind() == reflect.Array) &&
+		!T.Implements(typed) {
+		n := 0
+		if v.IsValid() {
+			n = v.Len()
+		}
+		dims = append(dims, n)
+		if n > 0 {
+			v = v.Index(0)
+		} else {
+			v = reflect.Value{}
+		}
+		T = T.Elem()
+	}
+	return dims, T
+}
+
+// The total number of elements in the shape
+func count(dims []int) int {
+	n := 1
+	for _, d := range dims {
+		n *= d
+	}
+	return n
+}
+
+// Whether the (possibly nested) value has the given shape, at every
+// level of nesting
+func conforms(v reflect.Value, dims []int) bool {
+	if len(dims) == 0 {
+		return true
+	}
+	if v.Len() != dims[0] {
+		return false
+	}
+	for i := 0; i < dims[0]; i++ {
+		if !conforms(v.Index(i), dims[1:]) {
+			return false
+		}
+	}
+	return true
+}
+
+// A variable-length C string, standing for a Go string held in a
+// structure (see shadow)
+type cstring struct{ p unsafe.Pointer }
+
+// The datatype of C strings
+func (cstring) Type() (h5t.Datatype, error) { return h5t.String(-1, true) }
+
+// The reflected cstring type
+var cstringType = reflect.TypeOf(cstring{})
+
+// The C layout of structures holding strings, which the library
+// expects as `char*` rather than Go strings: the strings (including
+// those of nested structures and arrays) are replaced by cstring.
+// This returns nil if the type holds no strings.
+func shadow(T reflect.Type) (reflect.Type, error) {
+	if T.Implements(typed) {
+		return nil, nil
+	}
+	switch T.Kind() {
+	case reflect.String:
+		return cstringType, nil
+	case reflect.Array:
+		E, err := shadow(T.Elem())
+		if E == nil || err != nil {
+			return nil, err
+		}
+		return reflect.ArrayOf(T.Len(), E), nil
+	case reflect.Struct:
+		fields := make([]reflect.StructField, T.NumField())
+		changed := false
+		for i := range fields {
+			fld := T.Field(i)
+			S, err := shadow(fld.Type)
+			if err != nil {
+				return nil, err
+			}
+			if S != nil {
+				fld.Type, changed = S, true
+			}
+			fld.Anonymous = false
+			fields[i] = fld
+		}
+		if !changed {
+			return nil, nil
+		}
+		for _, fld := range fields {
+			if fld.PkgPath != "" {
+				return nil, fmt.Errorf("Cannot store %s, which holds strings and unexported fields", T)
+			}
+		}
+		return reflect.StructOf(fields), nil
+	}
+	return nil, nil
+}
+
+// Checks that the elements hold no pointers, even in their fields or
+// arrays: the buffer would hold their addresses, while the library
+// expects the values they point to (see h5t.ParseType)
+func pointerFree(T reflect.Type) error {
+	if T.Implements(typed) {
+		return nil
+	}
+	switch T.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return fmt.Errorf("Cannot store elements of type %s, which holds pointers", T)
+	case reflect.Array:
+		return pointerFree(T.Elem())
+	case reflect.Struct:
+		for i := 0; i < T.NumField(); i++ {
+			if err := pointerFree(T.Field(i).Type); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Copies the Go value into its C layout (see shadow), allocating
+// C copies of its strings, which are appended to `cstrs`
+func toC(dst, src reflect.Value, cstrs *[]unsafe.Pointer) {
+	switch {
+	case dst.Type() == src.Type():
+		dst.Set(src)
+	case src.Kind() == reflect.String:
+		p := h5t.CStrings([]string{src.String()})[0]
+		*cstrs = append(*cstrs, p)
+		dst.Set(reflect.ValueOf(cstring{p}))
+	case src.Kind() == reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			toC(dst.Index(i), src.Index(i), cstrs)
+		}
+	default:
+		for i := 0; i < src.NumField(); i++ {
+			toC(dst.Field(i), src.Field(i), cstrs)
+		}
+	}
+}
+
+// Copies the C layout (see shadow) read from the library into the Go
+// value, releasing the memory of the strings
+func fromC(dst, src reflect.Value) {
+	switch {
+	case dst.Type() == src.Type():
+		dst.Set(src)
+	case dst.Kind() == reflect.String:
+		p := src.Interface().(cstring).p
+		dst.SetString(h5t.GoStrings([]unsafe.Pointer{p})[0])
+	case dst.Kind() == reflect.Array:
+		for i := 0; i < dst.Len(); i++ {
+			fromC(dst.Index(i), src.Index(i))
+		}
+	default:
+		for i := 0; i < dst.NumField(); i++ {
+			fromC(dst.Field(i), src.Field(i))
+		}
+	}
+}
+
+// Copies the elements of the (possibly nested) source into the
+// flat slice, starting at index k
+func flatten(dst, src reflect.Value, dims []int, k *int) error {
+	if len(dims) == 0 {
+		dst.Index(*k).Set(src)
+		*k++
+		return nil
+	}
+	if src.Len() != dims[0] {
+		return fmt.Errorf("Ragged arrays are not supported: expected length %v, got %v", dims[0], src.Len())
+	}
+	for i := 0; i < dims[0]; i++ {
+		if err := flatten(dst, src.Index(i), dims[1:], k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Copies the elements of the flat slice into the (possibly nested)
+// destination, starting at index k. Slices are allocated to the
+// right size, while arrays must already have the right length.
+func unflatten(dst, src reflect.Value, dims []int, k *int) error {
+	if len(dims) == 0 {
+		dst.Set(src.Index(*k))
+		*k++
+		return nil
+	}
+	switch dst.Kind() {
+	case reflect.Slice:
+		if dst.Len() != dims[0] {
+			dst.Set(reflect.MakeSlice(dst.Type(), dims[0], dims[0]))
+		}
+	case reflect.Array:
+		if dst.Len() != dims[0] {
+			return fmt.Errorf("Invalid array length: expecting %v, got %v", dims[0], dst.Len())
+		}
+	}
+	for i := 0; i < dims[0]; i++ {
+		if err := unflatten(dst.Index(i), src, dims[1:], k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Creates a new buffer holding a copy of the provided value, ready
+// to be written. Pointers to the value are dereferenced, but its
+// elements cannot be pointers.
+// The buffer should be closed once written
+func newBuffer(obj interface{}, ctxt core.Location) (*buffer, error) {
+	v := reflect.ValueOf(obj)
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil, fmt.Errorf("Nothing provided")
+	}
+	dims, elem := shapeof(v)
+	if err := pointerFree(elem); err != nil {
+		return nil, err
+	}
+	flat := reflect.MakeSlice(reflect.SliceOf(elem), count(dims), count(dims))
+	k := 0
+	if err := flatten(flat, v, dims, &k); err != nil {
+		return nil, err
+	}
+	out := &buffer{
+		dims: dims,
+		elem: elem,
+		raw:  flat,
+		base: -1,
+		ctxt: ctxt,
+	}
+	if elem.Kind() == reflect.String {
+		out.raw = reflect.ValueOf(h5t.CStrings(
+			flat.Interface().([]string)))
+		out.owned = true
+		return out, nil
+	}
+	mem, err := shadow(elem)
+	if err != nil || mem == nil {
+		return out, err
+	}
+	out.mem = mem
+	out.raw = reflect.MakeSlice(reflect.SliceOf(mem), flat.Len(), flat.Len())
+	for i := 0; i < flat.Len(); i++ {
+		toC(out.raw.Index(i), flat.Index(i), &out.cstrs)
+	}
+	return out, nil
+}
+
+// Allocates a new buffer of the given shape, to read elements of the
+// provided Go type. The `ftype` is the type of the data in the file,
+// which is used directly as memory type for strings.
+// The buffer should be closed once read
+func allocBuffer(dims []int, elem reflect.Type, ftype h5t.Datatype,
+	ctxt core.Location) (*buffer, error) {
+	if err := pointerFree(elem); err != nil {
+		return nil, err
+	}
+	n := count(dims)
+	out := &buffer{
+		dims: dims,
+		elem: elem,
+		base: -1,
+		ctxt: ctxt,
+	}
+	if elem.Kind() != reflect.String {
+		mem, err := shadow(elem)
+		if err != nil {
+			return nil, err
+		}
+		if mem == nil {
+			mem = elem
+		} else {
+			out.mem = mem
+		}
+		out.raw = reflect.MakeSlice(reflect.SliceOf(mem), n, n)
+		return out, nil
+	}
+	isvar, err := ftype.IsVarString()
+	if err != nil {
+		return nil, err
+	}
+	if out.base, err = ftype.Copy(); err != nil {
+		return nil, err
+	}
+	if isvar {
+		out.raw = reflect.ValueOf(make([]unsafe.Pointer, n))
+		return out, nil
+	}
+	size, err := ftype.GetSize()
+	if err == nil {
+		out.pad, err = ftype.GetStrPad()
+	}
+	if err != nil {
+		out.base.Close()
+		return nil, err
+	}
+	out.raw = reflect.ValueOf(make([]byte, n*size))
+	return out, nil
+}
+
+// The memory type of the elements
+func (b *buffer) Type() (h5t.Datatype, error) {
+	if b.base >= 0 {
+		return b.base.Copy()
+	}
+	if b.elem.Kind() == reflect.String {
+		return h5t.String(-1, true)
+	}
+	if b.mem != nil {
+		return h5t.ParseType(b.mem, b.ctxt)
+	}
+	return h5t.ParseType(b.elem, b.ctxt)
+}
+
+// Restricts the buffer to a single field of compound elements: its
+// memory type becomes a compound with only this field, holding the
+// elements of the buffer, so that only this field is read or written
+func (b *buffer) field(name string) error {
+	T, err := b.Type()
+	if err != nil {
+		return err
+	}
+	defer T.Close()
+	size, err := T.GetSize()
+	if err != nil {
+		return err
+	}
+	C, err := h5t.Struct(size, h5t.Field{Name: name, Type: T})
+	if err != nil {
+		return err
+	}
+	if b.base >= 0 {
+		b.base.Close()
+	}
+	b.base = C
+	return nil
+}
+
+// The memory dataspace of the buffer
+func (b *buffer) Shape() (h5s.Dataspace, error) {
+	if len(b.dims) == 0 {
+		return h5s.CreateScalar()
+	}
+	return h5s.CreateSimple(b.dims, nil)
+}
+
+// The pointer to the raw memory
+func (b *buffer) ptr() unsafe.Pointer {
+	if b.raw.Len() == 0 {
+		return nil
+	}
+	return unsafe.Pointer(b.raw.Pointer())
+}
+
+// Implements the h5d.Buffer interface
+func (b *buffer) ReadPtr() unsafe.Pointer  { return b.ptr() }
+func (b *buffer) WritePtr() unsafe.Pointer { return b.ptr() }
+
+// The flat slice of Go elements held by the buffer.
+// For strings read from a file (including those of structures), this
+// decodes (and releases) the memory filled by the library, so it
+// should only be called once.
+func (b *buffer) values() reflect.Value {
+	if b.mem != nil {
+		n := b.raw.Len()
+		out := reflect.MakeSlice(reflect.SliceOf(b.elem), n, n)
+		for i := 0; i < n; i++ {
+			fromC(out.Index(i), b.raw.Index(i))
+		}
+		return out
+	}
+	if b.elem.Kind() != reflect.String || b.owned {
+		return b.raw
+	}
+	switch raw := b.raw.Interface().(type) {
+	case []unsafe.Pointer:
+		return reflect.ValueOf(h5t.GoStrings(raw))
+	case []byte:
+		n := count(b.dims)
+		strs := make([]string, n)
+		if n == 0 {
+			return reflect.ValueOf(strs)
+		}
+		size := len(raw) / n
+		for i := range strs {
+			s := raw[i*size : (i+1)*size]
+			switch b.pad {
+			case h5t.SpacePad:
+				s = bytes.TrimRight(s, " ")
+			case h5t.NullPad:
+				s = bytes.TrimRight(s, "\x00")
+			default:
+				if end := bytes.IndexByte(s, 0); end >= 0 {
+					s = s[:end]
+				}
+			}
+			strs[i] = string(s)
+		}
+		return reflect.ValueOf(strs)
+	}
+	return b.raw
+}
+
+// Copies the content of the buffer into the value pointed by `out`
+func (b *buffer) decode(out reflect.Value) error {
+	k := 0
+	return unflatten(out, b.values(), b.dims, &k)
+}
+
+// Releases the resources held by the buffer
+func (b *buffer) Close() error {
+	if b.owned {
+		h5t.FreeCStrings(b.raw.Interface().([]unsafe.Pointer))
+		b.owned = false
+	}
+	h5t.FreeCStrings(b.cstrs)
+	b.cstrs = nil
+	if b.base >= 0 {
+		return b.base.Close()
+	}
+	return nil
+}
+
+// The reflected h5t.Typed interface
+var typed = reflect.TypeOf((*h5t.Typed)(nil)).Elem()
+
+// Checks that elements of the file type can be read into the Go
+// type. This only compares the broad classes of both types, leaving
+// the finer conversions (e.g. integer sizes) to the library.
+// As slices and arrays are interpreted as the shape of the data (see
+// shapeof), ARRAY and VLEN elements can only be read into types
+// implementing h5t.Typed.
+func compatible(ftype h5t.Datatype, elem reflect.Type) error {
+	cls, err := ftype.GetClass()
+	if err != nil {
+		return err
+	}
+	if elem.Implements(typed) {
+		T, err := reflect.Zero(elem).Interface().(h5t.Typed).Type()
+		if err != nil {
+			return err
+		}
+		defer T.Close()
+		if ecls, err := T.GetClass(); err != nil {
+			return err
+		} else if ecls != cls {
+			return fmt.Errorf("Cannot read %s data into %s",
+				cls.Name(), elem)
+		}
+		return nil
+	}
+	if cls == h5t.ARRAY || cls == h5t.VLEN {
+		return fmt.Errorf("Reading %s data is not supported (into %s)",
+			cls.Name(), elem)
+	}
+	var ok bool
+	switch elem.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		ok = cls == h5t.INTEGER || cls == h5t.FLOAT ||
+			cls == h5t.ENUM || cls == h5t.BITFIELD
+	case reflect.Float32, reflect.Float64:
+		ok = cls == h5t.INTEGER || cls == h5t.FLOAT
+	case reflect.Bool:
+		ok = cls == h5t.INTEGER || cls == h5t.ENUM
+	case reflect.String:
+		ok = cls == h5t.STRING
+	case reflect.Complex64, reflect.Complex128, reflect.Struct:
+		ok = cls == h5t.COMPOUND
+	default:
+		return fmt.Errorf("Cannot read into %s", elem)
+	}
+	if !ok {
+		return fmt.Errorf("Cannot read %s data into %s",
+			cls.Name(), elem)
+	}
+	return nil
+}
+
+// Reads data of the given file type and shape into the value pointed
+// by out, which should be a pointer to a scalar, a slice or an array
+// (possibly nested). Slices are allocated to the shape of the data,
+// and multi-dimensional data can be read into a flat slice.
+// The `read` function performs the actual read into the buffer.
+func readInto(ftype h5t.Datatype, dims []int,
+	ctxt core.Location, read func(h5d.OBuffer) error,
+	out interface{}) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("Expecting a non-nil pointer, got %T", out)
+	}
+	v = v.Elem()
+	depth, elem := shapeof(reflect.New(v.Type()).Elem())
+	if err := compatible(ftype, elem); err != nil {
+		return err
+	}
+	switch n := count(dims); {
+	case len(depth) == len(dims):
+	case len(depth) == 0 && n == 1:
+		dims = dims[:0]
+	case len(depth) == 1:
+		dims = []int{n}
+	default:
+		return fmt.Errorf("Cannot read %v-dimensional data into %s",
+			len(dims), v.Type())
+	}
+	buf, err := allocBuffer(dims, elem, ftype, ctxt)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+	if err := read(buf); err != nil {
+		return err
+	}
+	return buf.decode(v)
+}
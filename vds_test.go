@@ -0,0 +1,186 @@
+package h5go
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+import (
+	"github.com/valoox/h5go/h5d"
+	"github.com/valoox/h5go/h5s"
+	"github.com/valoox/h5go/h5t"
+)
+
+// Creates the source files of the virtual datasets, each with a row
+func vdsSources(t *testing.T, n int) []string {
+	var names []string
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("./vds-%d.h5", i)
+		f, err := Create(name, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		row := []int32{int32(10 * i), int32(10*i + 1), int32(10*i + 2), int32(10*i + 3)}
+		ds, err := f.NewDataset("x", row)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ds.Close()
+		f.Close()
+		names = append(names, name)
+	}
+	return names
+}
+
+// Checks that row i holds 10*i, 10*i+1...
+func checkRows(t *testing.T, rows [][]int32, n int) {
+	if len(rows) != n {
+		t.Fatalf("Expected %v rows, got %v", n, len(rows))
+	}
+	for i, row := range rows {
+		for j, x := range row {
+			if x != int32(10*i+j) {
+				t.Fatalf("Wrong value at (%v, %v): %v", i, j, x)
+			}
+		}
+	}
+}
+
+// Maps rows of a virtual dataset to source files, both one by one
+// and with a printf-like pattern over an unlimited dimension
+func TestVirtual(t *testing.T) {
+	const testfile = "./vds.h5"
+	sources := vdsSources(t, 2)
+	for _, name := range sources {
+		defer os.Remove(name)
+	}
+	defer os.Remove(testfile)
+	f, err := Create(testfile, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	T, err := h5t.Int32()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer T.Close()
+	src, err := h5s.CreateSimple([]int{4}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	// One mapping per row
+	var opts []Option
+	for i, name := range sources {
+		vspace, err := h5s.CreateSimple([]int{2, 4}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer vspace.Close()
+		if err := h5s.Hyperslab(vspace).Set([]uint{uint(i), 0}, nil,
+			[]uint{1, 4}, nil); err != nil {
+			t.Fatal(err)
+		}
+		opts = append(opts, Virtual(vspace, name, "x", src))
+	}
+	ds, err := f.CreateDataset("fixed", T, []int{2, 4}, nil, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ds.Close()
+	var rows [][]int32
+	if err := ds.ReadAll(&rows); err != nil {
+		t.Fatal(err)
+	}
+	checkRows(t, rows, 2)
+	crt, err := ds.Creation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer crt.Close()
+	if layout, err := crt.GetLayout(); err != nil {
+		t.Fatal(err)
+	} else if layout != h5d.Virtual {
+		t.Fatalf("Expected a virtual layout, got %v", layout)
+	}
+	maps, err := crt.GetVirtual()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(maps) != 2 {
+		t.Fatalf("Expected 2 mappings, got %v", len(maps))
+	}
+	for i, m := range maps {
+		defer m.Close()
+		if m.File != sources[i] || m.Dataset != "x" {
+			t.Fatalf("Wrong source of mapping %v: %s:%s", i, m.File, m.Dataset)
+		}
+		if n, err := m.VSpace.GetSelNPoints(); err != nil {
+			t.Fatal(err)
+		} else if n != 4 {
+			t.Fatalf("Expected 4 elements in mapping %v, got %v", i, n)
+		}
+	}
+
+	// A single mapping over an unlimited dimension
+	vspace, err := h5s.CreateSimple([]int{0, 4}, []int{-1, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vspace.Close()
+	if err := h5s.Hyperslab(vspace).Set([]uint{0, 0}, []uint{1, 1},
+		[]uint{h5s.UNLIMITED, 1}, []uint{1, 4}); err != nil {
+		t.Fatal(err)
+	}
+	ds, err = f.CreateDataset("daily", T, []int{0, 4}, []int{-1, 4},
+		Virtual(vspace, "./vds-%b.h5", "x", src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds.Close()
+	ds, err = f.OpenDataset("daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ds.Close()
+	if dims := ds.Dims(); len(dims) != 2 || dims[0] != 2 || dims[1] != 4 {
+		t.Fatalf("Expected dimensions [2 4], got %v", dims)
+	}
+	rows = nil
+	if err := ds.ReadAll(&rows); err != nil {
+		t.Fatal(err)
+	}
+	checkRows(t, rows, 2)
+}
+
+// Sets and gets the access properties of virtual datasets
+func TestVirtualAccess(t *testing.T) {
+	acc, err := h5d.Access()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer acc.Close()
+	if view, err := acc.GetVirtualView(); err != nil {
+		t.Fatal(err)
+	} else if view != h5d.LastAvailable {
+		t.Fatalf("Expected the last available view by default, got %v", view)
+	}
+	if err := acc.SetVirtualView(h5d.FirstMissing); err != nil {
+		t.Fatal(err)
+	}
+	if view, err := acc.GetVirtualView(); err != nil {
+		t.Fatal(err)
+	} else if view != h5d.FirstMissing {
+		t.Fatalf("Expected the first missing view, got %v", view)
+	}
+	if err := acc.SetVirtualPrintfGap(3); err != nil {
+		t.Fatal(err)
+	}
+	if gap, err := acc.GetVirtualPrintfGap(); err != nil {
+		t.Fatal(err)
+	} else if gap != 3 {
+		t.Fatalf("Expected a gap of 3, got %v", gap)
+	}
+}